@@ -0,0 +1,269 @@
+package zaptool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// adminDialTimeout is the time allowed to connect to an existing socket when checking if
+// it is stale.
+const adminDialTimeout = time.Second
+
+// ErrAdminSocketInUse is returned when another process is serving the admin socket.
+var ErrAdminSocketInUse = errors.New("admin socket address in use")
+
+// adminReadHeaderTimeout is the time allowed to read the headers of an admin request.
+const adminReadHeaderTimeout = 10 * time.Second
+
+// levelsResetter is implemented by a LogManager that can restore a snapshot (eg. LogLevels).
+type levelsResetter interface {
+	Snapshot() LevelSnapshot
+	RestoreBy(snapshot LevelSnapshot, src ChangeSource)
+}
+
+// resetHandler is the http.Handler that restores the levels captured when it was created.
+type resetHandler struct {
+	logmgr   LogManager
+	snapshot LevelSnapshot
+	opts     *levelsHandlerOptions
+}
+
+// ServeHTTP restores the levels on POST and cancels any active elevations.
+func (h resetHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeLevelsError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+
+		return
+	}
+
+	if !allowChange(w, req, h.opts) {
+		return
+	}
+
+	r, ok := h.logmgr.(levelsResetter)
+	if !ok {
+		writeLevelsError(w, http.StatusNotImplemented, errors.New("reset is not supported"))
+		return
+	}
+
+	src := ChangeSource{Reason: "reset to startup levels", Origin: "http " + req.RemoteAddr}
+	if h.opts.actor != nil {
+		src.Actor = h.opts.actor(req)
+	}
+
+	if c, ok := h.logmgr.(interface {
+		Elevations() []Elevation
+		CancelElevationsBy(pattern string, src ChangeSource) bool
+	}); ok {
+		cancelled := map[string]bool{}
+
+		for _, e := range c.Elevations() {
+			if !cancelled[e.Pattern] {
+				cancelled[e.Pattern] = c.CancelElevationsBy(e.Pattern, src)
+			}
+		}
+	}
+
+	r.RestoreBy(h.snapshot, src)
+
+	levelsHandler{h.logmgr, h.opts}.list(w)
+}
+
+// AdminHTTPHandler returns a http.Handler for administering the LogManager, it serves the
+// levels handler (see LevelsHTTPHandler) at /levels and resets the levels to those at the
+// time the handler was created on a POST to /levels/reset (if the LogManager supports
+// snapshots, eg. LogLevels). As with the levels handler a reset must be sent with a
+// Content-Type of application/json.
+//
+// A page at / lists the levels as a tree built from the dot-separated names and changes
+// them with the levels handler, it uses relative paths so when the handler is served under
+// a prefix (with http.StripPrefix) the page must be requested with a trailing slash.
+//
+// Changes are recorded without an actor unless an actor function is set with
+// LevelsHandlerOptionActor.
+func AdminHTTPHandler(logmgr LogManager, opts ...levelsHandlerOptionsFunc) http.Handler {
+	opt := &levelsHandlerOptions{
+		authorizer: nil,
+		actor:      nil,
+	}
+
+	for _, f := range opts {
+		f(opt)
+	}
+
+	reset := resetHandler{logmgr: logmgr, opts: opt}
+	if r, ok := logmgr.(levelsResetter); ok {
+		reset.snapshot = r.Snapshot()
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/levels", levelsHandler{logmgr, opt})
+	mux.Handle("/levels/reset", reset)
+	mux.Handle("/", adminUIHandler{})
+
+	return mux
+}
+
+// AdminServer serves the admin handler on a Unix domain socket.
+type AdminServer struct {
+	path     string
+	listener net.Listener
+	server   *http.Server
+	done     chan struct{}
+	err      error
+}
+
+// ListenAdminSocket serves the admin handler (see AdminHTTPHandler) for the LogManager on a
+// Unix domain socket at path, for use with the zaptool command. The socket is only
+// accessible by the owner, it is created in a temporary directory next to path and moved
+// into place once its permissions are set.
+//
+// On linux the user (and process id) connected to the socket is read from the socket
+// and recorded as the actor of changes, eg. "uid=1000(alice) pid=4321", an actor function
+// set with LevelsHandlerOptionActor replaces it.
+//
+// A stale socket left at the path (one that refuses connections) is removed, if another
+// process is still serving the socket an error wrapping ErrAdminSocketInUse is returned.
+func ListenAdminSocket(path string, logmgr LogManager, opts ...levelsHandlerOptionsFunc) (*AdminServer, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	listener, err := listenPrivateSocket(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &AdminServer{
+		path:     path,
+		listener: listener,
+		server: &http.Server{
+			Handler: AdminHTTPHandler(
+				logmgr,
+				append([]levelsHandlerOptionsFunc{LevelsHandlerOptionActor(requestPeerActor)}, opts...)...,
+			),
+			ReadHeaderTimeout: adminReadHeaderTimeout,
+			ConnContext: func(ctx context.Context, conn net.Conn) context.Context {
+				return context.WithValue(ctx, adminPeerKey{}, peerActor(conn))
+			},
+		},
+		done: make(chan struct{}),
+	}
+
+	go s.serve()
+
+	return s, nil
+}
+
+// adminPeerKey is the context key for the actor connected to the admin socket.
+type adminPeerKey struct{}
+
+// requestPeerActor returns the actor connected to the admin socket for the request.
+func requestPeerActor(req *http.Request) string {
+	actor, _ := req.Context().Value(adminPeerKey{}).(string)
+	return actor
+}
+
+// listenPrivateSocket listens on a Unix domain socket at path that is only accessible by the
+// owner, the socket is created in a private directory and only moved to path once its
+// permissions are set, so other users can not connect to it in between.
+func listenPrivateSocket(path string) (net.Listener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".zaptool-")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create admin socket directory: %w", err)
+	}
+
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	tmp := filepath.Join(dir, "admin.sock")
+
+	listener, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, fmt.Errorf("unable to listen on admin socket: %w", err)
+	}
+
+	// the socket is removed by AdminServer.Close once it has been moved to path.
+	if ul, ok := listener.(*net.UnixListener); ok {
+		ul.SetUnlinkOnClose(false)
+	}
+
+	if err := os.Chmod(tmp, 0o600); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("unable to set admin socket permissions: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("unable to move admin socket into place: %w", err)
+	}
+
+	return listener, nil
+}
+
+// removeStaleSocket removes the socket at path if nothing is listening on it.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&fs.ModeSocket == 0 {
+		// nothing to remove, or not a socket in which case listening reports the error.
+		return nil
+	}
+
+	conn, err := net.DialTimeout("unix", path, adminDialTimeout)
+	if err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("%w: %s", ErrAdminSocketInUse, path)
+	}
+
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		return fmt.Errorf("unable to check existing admin socket: %w", err)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("unable to remove stale admin socket: %w", err)
+	}
+
+	return nil
+}
+
+// serve serves requests until the server is closed.
+func (s *AdminServer) serve() {
+	defer close(s.done)
+
+	if err := s.server.Serve(s.listener); !errors.Is(err, http.ErrServerClosed) {
+		s.err = err
+	}
+}
+
+// Path returns the path of the socket.
+func (s *AdminServer) Path() string {
+	return s.path
+}
+
+// Close stops serving and removes the socket.
+func (s *AdminServer) Close() error {
+	err := s.server.Close()
+	<-s.done
+
+	if s.err != nil {
+		err = errors.Join(err, s.err)
+	}
+
+	if rmErr := os.Remove(s.path); rmErr != nil && !errors.Is(rmErr, fs.ErrNotExist) {
+		err = errors.Join(err, rmErr)
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to close admin socket: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package zaptool
+
+import (
+	"net"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// peerActor returns the user (and process) connected to the admin socket from the
+// credentials of the peer (SO_PEERCRED), it returns an empty string if they are not
+// available.
+func peerActor(conn net.Conn) string {
+	uc, ok := conn.(*net.UnixConn)
+	if !ok {
+		return ""
+	}
+
+	raw, err := uc.SyscallConn()
+	if err != nil {
+		return ""
+	}
+
+	var (
+		cred    *syscall.Ucred
+		credErr error
+	)
+
+	if err := raw.Control(func(fd uintptr) {
+		cred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	}); err != nil || credErr != nil {
+		return ""
+	}
+
+	return uidActor(cred.Uid, cred.Pid)
+}
+
+// uidActor returns the actor for a user id and process id in the form used by id(1).
+func uidActor(uid uint32, pid int32) string {
+	id := strconv.FormatUint(uint64(uid), 10)
+
+	actor := "uid=" + id
+	if u, err := user.LookupId(id); err == nil {
+		actor += "(" + u.Username + ")"
+	}
+
+	return actor + " pid=" + strconv.FormatInt(int64(pid), 10)
+}
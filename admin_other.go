@@ -0,0 +1,10 @@
+//go:build !linux
+
+package zaptool
+
+import "net"
+
+// peerActor returns an empty string as peer credentials are only read on linux.
+func peerActor(net.Conn) string {
+	return ""
+}
@@ -0,0 +1,171 @@
+package zaptool_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestAdminHTTPHandler(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	ll := zaptool.NewLogLevels(logger)
+	ll.Named("Server.Process")
+	ll.Named("Worker")
+
+	handler := zaptool.AdminHTTPHandler(ll)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Zaptool-User", "alice")
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	if rec := serve(http.MethodPut, "/levels", `{"name":"Server.*","level":"debug","for":"10m"}`); rec.Code != http.StatusOK {
+		t.Fatalf("set for duration: got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if elevations := ll.Elevations(); len(elevations) != 1 || elevations[0].Pattern != "Server.*" {
+		t.Errorf("level should be elevated: %v", elevations)
+	}
+
+	if rec := serve(http.MethodPut, "/levels", `{"name":"Worker","level":"error"}`); rec.Code != http.StatusOK {
+		t.Fatalf("set: got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if history := ll.History(); len(history) == 0 || history[len(history)-1].Source.Actor != "" {
+		t.Errorf("actor should not be taken from a request header: %+v", history)
+	}
+
+	if rec := serve(http.MethodGet, "/levels/reset", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("reset should only accept POST: got %d", rec.Code)
+	}
+
+	reset := httptest.NewRequest(http.MethodPost, "/levels/reset", nil)
+	reset.Header.Set("Content-Type", "text/plain")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, reset)
+
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("reset should require a JSON content type: got %d", rec.Code)
+	}
+
+	rec = serve(http.MethodPost, "/levels/reset", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("reset: got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if !strings.Contains(rec.Body.String(), `{"name":"Worker","level":"info"}`) {
+		t.Errorf("reset should return the levels: %s", rec.Body.String())
+	}
+
+	if ll.String() != "Internal.LogLevels:info,Server.Process:info,Worker:info" {
+		t.Errorf("levels should be reset: got '%s'", ll.String())
+	}
+
+	if elevations := ll.Elevations(); len(elevations) != 0 {
+		t.Errorf("reset should cancel elevations: %v", elevations)
+	}
+}
+
+func TestListenAdminSocket(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	ll := zaptool.NewLogLevels(logger)
+	ll.Named("Server.Process")
+
+	path := filepath.Join(t.TempDir(), "admin.sock")
+
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("unix sockets are not supported: %s", err)
+	}
+
+	if ul, ok := stale.(*net.UnixListener); ok {
+		ul.SetUnlinkOnClose(false)
+	}
+
+	_ = stale.Close()
+
+	srv, err := zaptool.ListenAdminSocket(path, ll)
+	if err != nil {
+		t.Fatalf("ListenAdminSocket returned error: %s", err)
+	}
+
+	if _, err := zaptool.ListenAdminSocket(path, ll); !errors.Is(err, zaptool.ErrAdminSocketInUse) {
+		t.Errorf("ListenAdminSocket should not take over a socket in use: %v", err)
+	}
+
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("socket should only be accessible by the owner: %v, %v", info, err)
+	}
+
+	if entries, err := os.ReadDir(filepath.Dir(path)); err != nil || len(entries) != 1 {
+		t.Errorf("only the socket should be left in the directory: %v, %v", entries, err)
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", srv.Path())
+			},
+		},
+	}
+
+	res, err := client.Get("http://zaptool/levels")
+	if err != nil {
+		t.Fatalf("request returned error: %s", err)
+	}
+
+	body, _ := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+
+	if !strings.Contains(string(body), `{"name":"Server.Process","level":"info"}`) {
+		t.Errorf("body: got '%s'", body)
+	}
+
+	res, err = client.Post("http://zaptool/levels", "application/json", strings.NewReader(`{"name":"Server.*","level":"debug"}`))
+	if err != nil {
+		t.Fatalf("request returned error: %s", err)
+	}
+
+	_ = res.Body.Close()
+
+	if runtime.GOOS == "linux" {
+		want := fmt.Sprintf("uid=%d", os.Getuid())
+		if history := ll.History(); len(history) == 0 || !strings.HasPrefix(history[len(history)-1].Source.Actor, want) {
+			t.Errorf("actor should be the user connected to the socket (%s): %+v", want, history)
+		}
+	}
+
+	if err := srv.Close(); err != nil {
+		t.Errorf("Close returned error: %s", err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Close should remove the socket: %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package zaptool
+
+import (
+	_ "embed"
+	"fmt"
+	"net/http"
+)
+
+// adminPage is the page served by the admin handler, it lists the levels as a tree and
+// changes them through the levels handler.
+//
+//go:embed adminui.html
+var adminPage []byte //nolint:gochecknoglobals // embedded file.
+
+// adminUIHandler is the http.Handler that serves the admin page.
+type adminUIHandler struct{}
+
+// ServeHTTP writes the admin page on GET.
+func (adminUIHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != "/" {
+		http.NotFound(w, req)
+		return
+	}
+
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, fmt.Sprintf("method %s not allowed", req.Method), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set(
+		"Content-Security-Policy",
+		"default-src 'none'; script-src 'unsafe-inline'; style-src 'unsafe-inline'; connect-src 'self'",
+	)
+
+	if req.Method == http.MethodHead {
+		return
+	}
+
+	_, _ = w.Write(adminPage)
+}
@@ -0,0 +1,85 @@
+package zaptool_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestAdminHTTPHandler_UI(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	ll := zaptool.NewLogLevels(logger)
+	ll.Named("Server.Process")
+
+	ts := httptest.NewServer(http.StripPrefix("/admin", zaptool.AdminHTTPHandler(ll)))
+	defer ts.Close()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		body   string
+	}{
+		{"page", http.MethodGet, "/admin/", http.StatusOK, "<title>Log Levels</title>"},
+		{"levels", http.MethodGet, "/admin/levels", http.StatusOK, `{"name":"Server.Process","level":"info"}`},
+		{"not found", http.MethodGet, "/admin/missing", http.StatusNotFound, ""},
+		{"method", http.MethodPost, "/admin/", http.StatusMethodNotAllowed, "method POST not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, ts.URL+tt.path, nil)
+			if err != nil {
+				t.Fatalf("NewRequest returned error: %s", err)
+			}
+
+			res, err := ts.Client().Do(req)
+			if err != nil {
+				t.Fatalf("request returned error: %s", err)
+			}
+			defer res.Body.Close()
+
+			body := &strings.Builder{}
+			_, _ = io.Copy(body, res.Body)
+
+			if res.StatusCode != tt.status {
+				t.Errorf("status: got %d, want %d", res.StatusCode, tt.status)
+			}
+
+			if !strings.Contains(body.String(), tt.body) {
+				t.Errorf("body: got '%s', want '%s'", body.String(), tt.body)
+			}
+		})
+	}
+}
+
+func TestAdminHTTPHandler_UIHeaders(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+
+	handler := zaptool.AdminHTTPHandler(zaptool.NewLogLevels(zap.New(fac)))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if ct := rec.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("content type: got '%s'", ct)
+	}
+
+	if csp := rec.Header().Get("Content-Security-Policy"); !strings.Contains(csp, "connect-src 'self'") {
+		t.Errorf("page should only connect to the admin handler: '%s'", csp)
+	}
+
+	if !strings.Contains(rec.Body.String(), `"Content-Type": "application/json"`) {
+		t.Error("page should send changes as JSON")
+	}
+}
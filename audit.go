@@ -0,0 +1,200 @@
+package zaptool
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultHistorySize is the number of level changes kept by a LogLevels by default.
+const defaultHistorySize = 100
+
+// LevelChangeAction is the type of change recorded in a LevelChange.
+type LevelChangeAction string
+
+const (
+	// LevelChangeSet is recorded by SetLevel and SetLevelBy.
+	LevelChangeSet LevelChangeAction = "set"
+	// LevelChangeClear is recorded by ClearLevel and ClearLevelBy.
+	LevelChangeClear LevelChangeAction = "clear"
+	// LevelChangeDelete is recorded by DeleteLevel and DeleteLevelBy.
+	LevelChangeDelete LevelChangeAction = "delete"
+	// LevelChangeRestore is recorded by Restore and RestoreBy.
+	LevelChangeRestore LevelChangeAction = "restore"
+	// LevelChangeElevate is recorded by ElevateLevel and ElevateLevelBy.
+	LevelChangeElevate LevelChangeAction = "elevate"
+	// LevelChangeCancelElevation is recorded by CancelElevations and CancelElevationsBy.
+	LevelChangeCancelElevation LevelChangeAction = "cancel-elevation"
+	// LevelChangeExpire is recorded when an elevation expires.
+	LevelChangeExpire LevelChangeAction = "expire"
+	// LevelChangeSetFloor is recorded by SetLevelFloor and SetLevelFloorBy.
+	LevelChangeSetFloor LevelChangeAction = "set-floor"
+	// LevelChangeClearFloor is recorded by ClearLevelFloor and ClearLevelFloorBy.
+	LevelChangeClearFloor LevelChangeAction = "clear-floor"
+	// LevelChangeSetOverride is recorded by SetLevelOverride and SetLevelOverrideBy.
+	LevelChangeSetOverride LevelChangeAction = "set-override"
+	// LevelChangeClearOverride is recorded by ClearLevelOverride and ClearLevelOverrideBy.
+	LevelChangeClearOverride LevelChangeAction = "clear-override"
+)
+
+// ChangeSource describes who made a level change, why and from where.
+type ChangeSource struct {
+	// Actor is who made the change, eg. the user of an admin API.
+	Actor string `json:"actor,omitempty"`
+	// Reason is why the change was made.
+	Reason string `json:"reason,omitempty"`
+	// Origin is where the change came from, eg. "http 10.0.0.1:5123", "signal" or "file".
+	Origin string `json:"origin,omitempty"`
+}
+
+// LevelChange is a level change recorded in the history of a LogLevels.
+type LevelChange struct {
+	Time    time.Time         `json:"time"`
+	Action  LevelChangeAction `json:"action"`
+	Pattern string            `json:"pattern,omitempty"`
+	// Level is the level that was set, it is empty for other actions.
+	Level string `json:"level,omitempty"`
+	// Duration is how long an elevation lasts, it is zero for other actions.
+	Duration time.Duration `json:"duration,omitempty"`
+	// Matched is true if any existing levels matched the pattern (or for the level floor
+	// and override, if it was set).
+	Matched bool         `json:"matched"`
+	Source  ChangeSource `json:"source"`
+}
+
+// LogLevelsHistorySize sets the number of level changes kept in the history, the oldest
+// changes are discarded first. The default is 100, zero disables the history.
+func LogLevelsHistorySize(size int) func(*LogLevels) {
+	return func(ll *LogLevels) {
+		ll.historyLock.Lock()
+		defer ll.historyLock.Unlock()
+
+		if size < 0 {
+			size = 0
+		}
+
+		ll.historySize = size
+		ll.trimHistory()
+	}
+}
+
+// LogLevelsAuditLogger writes every level change to the logger as an info entry, eg. a
+// logger routed to a separate audit file.
+func LogLevelsAuditLogger(logger *zap.Logger) func(*LogLevels) {
+	return func(ll *LogLevels) {
+		ll.historyLock.Lock()
+		defer ll.historyLock.Unlock()
+
+		ll.auditLogger = logger
+	}
+}
+
+// History returns a copy of the recorded level changes, oldest first.
+func (a *LogLevels) History() []LevelChange {
+	a.historyLock.Lock()
+	defer a.historyLock.Unlock()
+
+	out := make([]LevelChange, len(a.history))
+	copy(out, a.history)
+
+	return out
+}
+
+// setLevelBy calls SetLevelBy if the LogManager records the source of changes (eg. LogLevels),
+// otherwise it calls SetLevel.
+func setLevelBy(logmgr LogManager, name string, lvl interface{}, src ChangeSource) bool {
+	if s, ok := logmgr.(interface {
+		SetLevelBy(name string, lvl interface{}, src ChangeSource) bool
+	}); ok {
+		return s.SetLevelBy(name, lvl, src)
+	}
+
+	return logmgr.SetLevel(name, lvl)
+}
+
+// clearLevelBy calls ClearLevelBy or ClearLevel if the LogManager supports them, it returns
+// false if it supports neither.
+func clearLevelBy(logmgr LogManager, name string, src ChangeSource) bool {
+	switch c := logmgr.(type) {
+	case interface {
+		ClearLevelBy(name string, src ChangeSource) bool
+	}:
+		return c.ClearLevelBy(name, src)
+	case interface{ ClearLevel(name string) bool }:
+		return c.ClearLevel(name)
+	}
+
+	return false
+}
+
+// deleteLevelBy calls DeleteLevelBy if the LogManager records the source of changes (eg.
+// LogLevels), otherwise it calls DeleteLevel.
+func deleteLevelBy(logmgr LogManager, name string, src ChangeSource) {
+	if d, ok := logmgr.(interface {
+		DeleteLevelBy(name string, src ChangeSource)
+	}); ok {
+		d.DeleteLevelBy(name, src)
+		return
+	}
+
+	logmgr.DeleteLevel(name)
+}
+
+// elevateLevelBy calls ElevateLevelBy or ElevateLevel if the LogManager supports them, the
+// second value is false if it supports neither.
+func elevateLevelBy(logmgr LogManager, name string, lvl interface{}, d time.Duration, src ChangeSource) (bool, bool) {
+	switch e := logmgr.(type) {
+	case interface {
+		ElevateLevelBy(name string, lvl interface{}, d time.Duration, src ChangeSource) bool
+	}:
+		return e.ElevateLevelBy(name, lvl, d, src), true
+	case interface {
+		ElevateLevel(name string, lvl interface{}, d time.Duration) bool
+	}:
+		return e.ElevateLevel(name, lvl, d), true
+	}
+
+	return false, false
+}
+
+// record adds the change to the history and writes it to the audit logger.
+//
+// Callers must not hold the lock.
+func (a *LogLevels) record(change LevelChange) {
+	change.Time = time.Now()
+
+	a.historyLock.Lock()
+	a.history = append(a.history, change)
+	a.trimHistory()
+	auditLogger := a.auditLogger
+	a.historyLock.Unlock()
+
+	if auditLogger == nil {
+		return
+	}
+
+	fields := []zap.Field{
+		zap.String("action", string(change.Action)),
+		zap.String("pattern", change.Pattern),
+		zap.String("level", change.Level),
+		zap.Bool("matched", change.Matched),
+		zap.String("actor", change.Source.Actor),
+		zap.String("reason", change.Source.Reason),
+		zap.String("origin", change.Source.Origin),
+	}
+
+	if change.Duration > 0 {
+		fields = append(fields, zap.Duration("duration", change.Duration))
+	}
+
+	auditLogger.Info("log level changed", fields...)
+}
+
+// trimHistory discards the oldest changes over the history size.
+//
+// Callers must hold the history lock.
+func (a *LogLevels) trimHistory() {
+	if over := len(a.history) - a.historySize; over > 0 {
+		a.history = append(a.history[:0], a.history[over:]...)
+	}
+}
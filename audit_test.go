@@ -0,0 +1,233 @@
+package zaptool_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogLevels_History(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	auditFac, auditLogs := observer.New(zapcore.InfoLevel)
+
+	loglvls := zaptool.NewLogLevels(logger, zaptool.LogLevelsAuditLogger(zap.New(auditFac)))
+	loglvls.Named("Server.Process")
+
+	src := zaptool.ChangeSource{Actor: "bob", Reason: "investigating", Origin: "test"}
+
+	loglvls.SetLevelBy("Server.*", "debug", src)
+	loglvls.ClearLevelBy("Server.*", src)
+	loglvls.DeleteLevelBy("Client", src)
+	loglvls.SetLevel("Server.Process", zapcore.WarnLevel)
+
+	history := loglvls.History()
+	if len(history) != 4 {
+		t.Fatalf("History should contain 4 changes, instead contained %d", len(history))
+	}
+
+	tests := []struct {
+		action  zaptool.LevelChangeAction
+		pattern string
+		level   string
+		matched bool
+		actor   string
+	}{
+		{zaptool.LevelChangeSet, "Server.*", "debug", true, "bob"},
+		{zaptool.LevelChangeClear, "Server.*", "", true, "bob"},
+		{zaptool.LevelChangeDelete, "Client", "", false, "bob"},
+		{zaptool.LevelChangeSet, "Server.Process", "warn", true, ""},
+	}
+
+	for idx, tt := range tests {
+		change := history[idx]
+
+		if change.Action != tt.action || change.Pattern != tt.pattern || change.Level != tt.level ||
+			change.Matched != tt.matched || change.Source.Actor != tt.actor {
+			t.Errorf("History[%d]: got %+v, want %+v", idx, change, tt)
+		}
+
+		if change.Time.IsZero() {
+			t.Errorf("History[%d]: time should be set", idx)
+		}
+	}
+
+	if history[0].Source != src {
+		t.Errorf("History[0]: source got %+v, want %+v", history[0].Source, src)
+	}
+
+	logs := auditLogs.FilterMessage("log level changed").All()
+	if len(logs) != 4 {
+		t.Fatalf("audit logger should contain 4 entries, instead contained %d", len(logs))
+	}
+
+	if fields := logs[0].ContextMap(); fields["actor"] != "bob" || fields["reason"] != "investigating" ||
+		fields["pattern"] != "Server.*" || fields["level"] != "debug" {
+		t.Errorf("audit entry fields: got %v", fields)
+	}
+}
+
+func TestLogLevels_HistorySize(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger, zaptool.LogLevelsHistorySize(2))
+
+	loglvls.SetLevel("A", "debug")
+	loglvls.SetLevel("B", "debug")
+	loglvls.SetLevel("C", "debug")
+
+	history := loglvls.History()
+	if len(history) != 2 || history[0].Pattern != "B" || history[1].Pattern != "C" {
+		t.Errorf("History should only keep the latest 2 changes: %+v", history)
+	}
+
+	disabled := zaptool.NewLogLevels(logger, zaptool.LogLevelsHistorySize(0))
+	disabled.SetLevel("A", "debug")
+
+	if history := disabled.History(); len(history) != 0 {
+		t.Errorf("History should be empty when disabled: %+v", history)
+	}
+}
+
+func TestLogLevels_HistorySources(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.Named("Server.Process")
+
+	handler := zaptool.LevelsHTTPHandler(
+		zaptool.NewSubLogLevels("Server", loglvls),
+		zaptool.LevelsHandlerOptionActor(func(r *http.Request) string {
+			return r.Header.Get("X-User")
+		}),
+	)
+
+	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"name":"Process","level":"debug","reason":"ticket 123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User", "alice")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	path := filepath.Join(t.TempDir(), "levels.yaml")
+	if err := os.WriteFile(path, []byte("Server.Process: warn\n"), 0o600); err != nil {
+		t.Fatalf("unable to write level file: %s", err)
+	}
+
+	watcher := zaptool.WatchLevelFile(path, loglvls, time.Hour)
+	watcher.Stop()
+
+	history := loglvls.History()
+	if len(history) != 2 {
+		t.Fatalf("History should contain 2 changes, instead contained %d: %+v", len(history), history)
+	}
+
+	if src := history[0].Source; src.Actor != "alice" || src.Reason != "ticket 123" ||
+		!strings.HasPrefix(src.Origin, "http ") || history[0].Pattern != "Server.Process" {
+		t.Errorf("handler change: got %+v", history[0])
+	}
+
+	if src := history[1].Source; src.Origin != "file" || !strings.Contains(src.Reason, path) {
+		t.Errorf("level file change: got %+v", history[1])
+	}
+}
+
+func TestLogLevels_HistoryIncidentChanges(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.Named("Server.Process")
+
+	src := zaptool.ChangeSource{Actor: "alice", Reason: "INC-123", Origin: "console"}
+
+	if err := loglvls.ApplySpecBy("*=warn,Server.*=debug", src); err != nil {
+		t.Fatalf("ApplySpecBy returned error: %s", err)
+	}
+
+	loglvls.ElevateLevelBy("Server.*", zapcore.DebugLevel, time.Hour, src)
+	loglvls.CancelElevationsBy("Server.*", src)
+	loglvls.ElevateLevel("Server.*", zapcore.DebugLevel, 50*time.Millisecond)
+	loglvls.SetLevelFloorBy(zapcore.WarnLevel, src)
+	loglvls.ClearLevelFloorBy(src)
+	loglvls.SetLevelOverrideBy(zapcore.ErrorLevel, src)
+	loglvls.ClearLevelOverride()
+
+	// the expiry is recorded after the elevation is removed, so wait for the record.
+	deadline := time.Now().Add(time.Second)
+	for !lastChangeIs(loglvls.History(), zaptool.LevelChangeExpire) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	history := loglvls.History()
+
+	got := make([]string, 0, len(history))
+	for _, change := range history {
+		got = append(got, fmt.Sprintf("%s %s %s %s", change.Action, change.Pattern, change.Level, change.Source.Actor))
+	}
+
+	want := []string{
+		"set * warn alice",
+		"set Server.* debug alice",
+		"elevate Server.* debug alice",
+		"cancel-elevation Server.*  alice",
+		"elevate Server.* debug ",
+		"set-floor  warn alice",
+		"clear-floor   alice",
+		"set-override  error alice",
+		"clear-override   ",
+		"expire Server.* debug ",
+	}
+
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("History:\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if history[2].Duration != time.Hour {
+		t.Errorf("elevation should record its duration: %+v", history[2])
+	}
+
+	if history[len(history)-1].Source.Origin != "timer" {
+		t.Errorf("expired elevation should be recorded with the timer origin: %+v", history[len(history)-1])
+	}
+}
+
+func TestLevelsHTTPHandler_ElevateSource(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.Named("Server.Process")
+
+	handler := zaptool.LevelsHTTPHandler(loglvls, zaptool.LevelsHandlerOptionActor(func(*http.Request) string {
+		return "alice"
+	}))
+
+	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"name":"Server.*","level":"debug","for":"10m","reason":"INC-123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	history := loglvls.History()
+	if len(history) != 1 || history[0].Action != zaptool.LevelChangeElevate {
+		t.Fatalf("History should contain the elevation: %+v", history)
+	}
+
+	if src := history[0].Source; src.Actor != "alice" || src.Reason != "INC-123" || !strings.HasPrefix(src.Origin, "http ") {
+		t.Errorf("elevation should record the request source: %+v", src)
+	}
+}
+
+func lastChangeIs(history []zaptool.LevelChange, action zaptool.LevelChangeAction) bool {
+	return len(history) > 0 && history[len(history)-1].Action == action
+}
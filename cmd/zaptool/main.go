@@ -0,0 +1,234 @@
+// Command zaptool changes the log levels of a running process through the admin socket
+// served by zaptool.ListenAdminSocket.
+//
+//	zaptool levels list
+//	zaptool levels set 'Server.*' debug --for 10m --reason INC-123
+//	zaptool levels reset
+//
+// The socket is set with -socket or the ZAPTOOL_SOCKET environment variable.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/na4ma4/go-zaptool"
+)
+
+// defaultSocket is the socket used when neither -socket nor ZAPTOOL_SOCKET is set.
+const defaultSocket = "/tmp/zaptool.sock"
+
+// columnPadding is the padding between the columns of the levels table.
+const columnPadding = 2
+
+// requestTimeout is the time allowed for a request to the admin socket.
+const requestTimeout = 30 * time.Second
+
+// exit codes.
+const (
+	exitError = 1
+	exitUsage = 2
+)
+
+// errUsage is returned when the command line is invalid.
+var errUsage = errors.New("invalid usage")
+
+const usage = `usage: zaptool [-socket path] levels <command>
+
+commands:
+  list                                         list the named levels
+  set <pattern> <level> [-for d] [-reason r]   set the level of matching loggers
+  reset                                        reset the levels to those at startup
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command with the arguments and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	socket := os.Getenv("ZAPTOOL_SOCKET")
+	if socket == "" {
+		socket = defaultSocket
+	}
+
+	fset := flag.NewFlagSet("zaptool", flag.ContinueOnError)
+	fset.SetOutput(stderr)
+	fset.Usage = func() { fmt.Fprint(stderr, usage) }
+	fset.StringVar(&socket, "socket", socket, "path of the admin socket")
+
+	if err := fset.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	c := client{socket: socket}
+
+	err := c.levels(fset.Args(), stdout, stderr)
+
+	switch {
+	case errors.Is(err, errUsage):
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	case err != nil:
+		fmt.Fprintf(stderr, "zaptool: %s\n", err)
+		return exitError
+	}
+
+	return 0
+}
+
+// client sends requests to the admin socket.
+type client struct {
+	socket string
+}
+
+// levels runs a levels command.
+func (c client) levels(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 || args[0] != "levels" {
+		return errUsage
+	}
+
+	switch cmd := args[1:]; {
+	case len(cmd) == 1 && cmd[0] == "list":
+		return c.do(http.MethodGet, "/levels", nil, stdout)
+	case len(cmd) > 1 && cmd[0] == "set":
+		return c.set(cmd[1:], stdout, stderr)
+	case len(cmd) == 1 && cmd[0] == "reset":
+		return c.do(http.MethodPost, "/levels/reset", nil, stdout)
+	default:
+		return errUsage
+	}
+}
+
+// setRequest is the body of a request to set a level.
+type setRequest struct {
+	Name   string `json:"name"`
+	Level  string `json:"level"`
+	Reason string `json:"reason,omitempty"`
+	For    string `json:"for,omitempty"`
+}
+
+// set sets the level of the pattern, flags may appear before or after the pattern and level.
+func (c client) set(args []string, stdout, stderr io.Writer) error {
+	var (
+		req setRequest
+		d   time.Duration
+	)
+
+	fset := flag.NewFlagSet("set", flag.ContinueOnError)
+	fset.SetOutput(stderr)
+	fset.Usage = func() {}
+	fset.DurationVar(&d, "for", 0, "only set the level for the duration")
+	fset.StringVar(&req.Reason, "reason", "", "reason for the change")
+
+	positional, err := parseInterspersed(fset, args)
+	if err != nil || len(positional) != 2 {
+		return errUsage
+	}
+
+	req.Name, req.Level = positional[0], positional[1]
+
+	if d < 0 {
+		return fmt.Errorf("invalid duration: %s", d)
+	}
+
+	if d > 0 {
+		req.For = d.String()
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("unable to encode request: %w", err)
+	}
+
+	return c.do(http.MethodPut, "/levels", body, stdout)
+}
+
+// parseInterspersed parses the flags in args and returns the remaining arguments, unlike
+// flag.Parse flags may follow the arguments.
+func parseInterspersed(fset *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+
+	for {
+		if err := fset.Parse(args); err != nil {
+			return nil, fmt.Errorf("unable to parse flags: %w", err)
+		}
+
+		args = fset.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// httpClient returns a http.Client that connects to the admin socket.
+func (c client) httpClient() *http.Client {
+	return &http.Client{
+		Timeout: requestTimeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", c.socket)
+			},
+		},
+	}
+}
+
+// do sends the request and writes the levels in the response as a table.
+func (c client) do(method, path string, body []byte, stdout io.Writer) error {
+	req, err := http.NewRequestWithContext(context.Background(), method, "http://zaptool"+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to connect to %s: %w", c.socket, err)
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		Levels []zaptool.LevelItem `json:"levels"`
+		Error  string              `json:"error"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return fmt.Errorf("unable to decode response (%s): %w", resp.Status, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if out.Error == "" {
+			out.Error = resp.Status
+		}
+
+		return errors.New(out.Error) //nolint:goerr113 // error returned by the process.
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 0, columnPadding, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tLEVEL")
+
+	for _, item := range out.Levels {
+		fmt.Fprintf(tw, "%s\t%s\n", item.Name, item.Level)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("unable to write levels: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRun(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	ll := zaptool.NewLogLevels(logger)
+	ll.Named("Server.Process")
+	ll.Named("Worker")
+
+	path := filepath.Join(t.TempDir(), "admin.sock")
+
+	srv, err := zaptool.ListenAdminSocket(path, ll)
+	if err != nil {
+		t.Skipf("unable to listen on admin socket: %s", err)
+	}
+	defer srv.Close()
+
+	t.Setenv("ZAPTOOL_SOCKET", path)
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string
+		stderr string
+		levels string
+	}{
+		{
+			name:   "list",
+			args:   []string{"levels", "list"},
+			stdout: "NAME                LEVEL\nInternal.LogLevels  info\nServer.Process      info\nWorker              info\n",
+			levels: "Internal.LogLevels:info,Server.Process:info,Worker:info",
+		},
+		{
+			name:   "set",
+			args:   []string{"levels", "set", "Worker", "error", "--reason", "INC-123"},
+			stdout: "Worker              error\n",
+			levels: "Internal.LogLevels:info,Server.Process:info,Worker:error",
+		},
+		{
+			name:   "set for duration",
+			args:   []string{"levels", "set", "--for", "10m", "Server.*", "debug"},
+			stdout: "Server.Process      debug\n",
+			levels: "Internal.LogLevels:info,Server.Process:debug,Worker:error",
+		},
+		{
+			name:   "set no match",
+			args:   []string{"levels", "set", "Client.*", "debug"},
+			code:   exitError,
+			stderr: `zaptool: no loggers match name: "Client.*"`,
+			levels: "Internal.LogLevels:info,Server.Process:debug,Worker:error",
+		},
+		{
+			name:   "reset",
+			args:   []string{"levels", "reset"},
+			stdout: "Worker              info\n",
+			levels: "Internal.LogLevels:info,Server.Process:info,Worker:info",
+		},
+		{
+			name:   "missing level",
+			args:   []string{"levels", "set", "Worker"},
+			code:   exitUsage,
+			stderr: "usage: zaptool",
+		},
+		{
+			name:   "unknown command",
+			args:   []string{"levels", "remove"},
+			code:   exitUsage,
+			stderr: "usage: zaptool",
+		},
+		{
+			name:   "missing socket",
+			args:   []string{"-socket", filepath.Join(t.TempDir(), "missing.sock"), "levels", "list"},
+			code:   exitError,
+			stderr: "zaptool: unable to connect to",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+			if code := run(tt.args, stdout, stderr); code != tt.code {
+				t.Errorf("exit code: got %d, want %d (%s)", code, tt.code, stderr.String())
+			}
+
+			if !strings.Contains(stdout.String(), tt.stdout) {
+				t.Errorf("stdout: got '%s', want '%s'", stdout.String(), tt.stdout)
+			}
+
+			if !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("stderr: got '%s', want '%s'", stderr.String(), tt.stderr)
+			}
+
+			if tt.levels != "" && ll.String() != tt.levels {
+				t.Errorf("levels: got '%s', want '%s'", ll.String(), tt.levels)
+			}
+		})
+	}
+
+	if history := ll.History(); len(history) == 0 || history[0].Source.Reason != "INC-123" {
+		t.Errorf("changes should record the reason: %+v", history)
+	}
+
+	if history := ll.History(); runtime.GOOS == "linux" && !strings.HasPrefix(history[0].Source.Actor, fmt.Sprintf("uid=%d", os.Getuid())) {
+		t.Errorf("changes should record the user running zaptool: %+v", history)
+	}
+}
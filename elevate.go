@@ -0,0 +1,278 @@
+package zaptool
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Elevation is a temporary level applied to names matching the pattern until it expires.
+type Elevation struct {
+	Pattern string
+	Level   zapcore.Level
+	Expires time.Time
+}
+
+// elevation is an active Elevation and the timer that removes it.
+type elevation struct {
+	Elevation
+
+	timer *time.Timer
+}
+
+// ElevateLevel raises the level of names matching the name (or wildcard pattern) to the
+// supplied level for the duration, after which they revert to their configured level.
+// Loggers created while the elevation is active that match it are also raised.
+//
+// Overlapping elevations are combined, the most verbose active elevation applies and
+// levels revert once every elevation matching them has expired. Levels set while an
+// elevation is active become the level reverted to.
+//
+// It returns true if any existing levels matched (as for SetLevel, including levels that
+// inherit from a matching ancestor), if none match the elevation is not added.
+func (a *LogLevels) ElevateLevel(name string, lvl interface{}, d time.Duration) bool {
+	return a.ElevateLevelBy(name, lvl, d, ChangeSource{})
+}
+
+// ElevateLevelBy is ElevateLevel with the source of the change recorded in the history (and
+// audit logger if there is one).
+func (a *LogLevels) ElevateLevelBy(name string, lvl interface{}, d time.Duration, src ChangeSource) bool {
+	name = a.normaliseName(name)
+	a.iLogger.Debug("ElevateLevel", zap.String("name", name), zap.Duration("duration", d))
+
+	level, ok := parseLevel(lvl)
+	if !ok || d <= 0 {
+		return false
+	}
+
+	a.lock.Lock()
+	found := a.elevate(name, level, d)
+	a.lock.Unlock()
+
+	a.record(LevelChange{
+		Action:   LevelChangeElevate,
+		Pattern:  name,
+		Level:    level.String(),
+		Duration: d,
+		Matched:  found,
+		Source:   src,
+	})
+
+	return found
+}
+
+// elevate adds an elevation for the pattern if any existing levels match it, it returns
+// true if the elevation was added.
+//
+// Callers must hold the lock.
+func (a *LogLevels) elevate(name string, level zapcore.Level, d time.Duration) bool {
+	if !a.anyMatch(name) {
+		return false
+	}
+
+	elev := &elevation{
+		Elevation: Elevation{
+			Pattern: name,
+			Level:   level,
+			Expires: time.Now().Add(d),
+		},
+	}
+	elev.timer = time.AfterFunc(d, func() {
+		a.expireElevation(elev)
+	})
+
+	a.elevations = append(a.elevations, elev)
+
+	a.refreshLevels(name)
+
+	return true
+}
+
+// Elevations returns the active elevations ordered by when they expire.
+func (a *LogLevels) Elevations() []Elevation {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	out := make([]Elevation, 0, len(a.elevations))
+	for _, elev := range a.elevations {
+		out = append(out, elev.Elevation)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Expires.Before(out[j].Expires)
+	})
+
+	return out
+}
+
+// CancelElevations removes all active elevations with the pattern, matching levels revert
+// to their configured level. It returns true if any elevations were removed.
+func (a *LogLevels) CancelElevations(pattern string) bool {
+	return a.CancelElevationsBy(pattern, ChangeSource{})
+}
+
+// CancelElevationsBy is CancelElevations with the source of the change recorded in the
+// history (and audit logger if there is one).
+func (a *LogLevels) CancelElevationsBy(pattern string, src ChangeSource) bool {
+	pattern = a.normaliseName(pattern)
+
+	a.lock.Lock()
+	found := a.cancelElevations(pattern)
+	a.lock.Unlock()
+
+	a.record(LevelChange{
+		Action:  LevelChangeCancelElevation,
+		Pattern: pattern,
+		Matched: found,
+		Source:  src,
+	})
+
+	return found
+}
+
+// cancelElevations removes the elevations with the pattern, it returns true if any were
+// removed.
+//
+// Callers must hold the lock.
+func (a *LogLevels) cancelElevations(pattern string) bool {
+	found := false
+
+	for idx := 0; idx < len(a.elevations); idx++ {
+		if a.elevations[idx].Pattern == pattern {
+			a.elevations[idx].timer.Stop()
+			a.elevations = append(a.elevations[:idx], a.elevations[idx+1:]...)
+			idx--
+
+			found = true
+		}
+	}
+
+	if found {
+		a.refreshLevels(pattern)
+	}
+
+	return found
+}
+
+// expireElevation removes the elevation and reverts the levels it raised.
+func (a *LogLevels) expireElevation(elev *elevation) {
+	a.lock.Lock()
+
+	found := false
+
+	for idx, item := range a.elevations {
+		if item == elev {
+			a.elevations = append(a.elevations[:idx], a.elevations[idx+1:]...)
+
+			a.iLogger.Debug(
+				"elevation expired",
+				zap.String("name", elev.Pattern),
+				zap.String("level", elev.Level.String()),
+			)
+
+			a.refreshLevels(elev.Pattern)
+
+			found = true
+
+			break
+		}
+	}
+
+	a.lock.Unlock()
+
+	if found {
+		a.record(LevelChange{
+			Action:  LevelChangeExpire,
+			Pattern: elev.Pattern,
+			Level:   elev.Level.String(),
+			Matched: true,
+			Source:  ChangeSource{Origin: "timer", Reason: "elevation expired"},
+		})
+	}
+}
+
+// refreshLevels reapplies the configured level of every entry, taking into account the
+// active elevations, parents are refreshed before their children.
+//
+// Callers must hold the lock.
+func (a *LogLevels) refreshLevels(cause string) {
+	names := make([]string, 0, len(a.levels))
+	for k := range a.levels {
+		names = append(names, k)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return strings.Count(names[i], ".") < strings.Count(names[j], ".")
+	})
+
+	for _, name := range names {
+		entry := a.levels[name]
+		a.setEntryLevel(name, entry, entry.configured, cause)
+	}
+
+	a.inherit(cause)
+}
+
+// setEntryLevel sets the configured level for the entry and notifies subscribers if the
+// effective level changed, cause is the name or pattern responsible for the change.
+//
+// Callers must hold the lock.
+func (a *LogLevels) setEntryLevel(name string, entry *levelEntry, level zapcore.Level, cause string) {
+	entry.configured = level
+
+	old := entry.level.Level()
+	if effective := a.effectiveLevel(name, level, entry.explicit); old != effective {
+		entry.level.SetLevel(effective)
+		a.publish(LevelEvent{Name: name, Old: old, New: effective, Pattern: cause, Time: time.Now()})
+	}
+}
+
+// effectiveLevel returns the most verbose of the level and any active elevations that
+// match the name, a level that is not explicitly set is also raised by the elevations of
+// the ancestors it inherits from.
+//
+// Callers must hold the lock.
+func (a *LogLevels) effectiveLevel(name string, level zapcore.Level, explicit bool) zapcore.Level {
+	level = a.elevatedLevel(name, level)
+
+	if explicit {
+		return level
+	}
+
+	for parent, ok := parentName(name); ok; parent, ok = parentName(parent) {
+		// the effective level of an existing ancestor includes its own elevations (and those
+		// it inherits).
+		if v, found := a.levels[parent]; found {
+			if v.level.Level() < level {
+				level = v.level.Level()
+			}
+
+			break
+		}
+
+		level = a.elevatedLevel(parent, level)
+
+		if _, found := a.ruleLevel(parent); found {
+			break
+		}
+	}
+
+	return level
+}
+
+// elevatedLevel returns the most verbose of the level and any active elevations that match
+// the name.
+//
+// Callers must hold the lock.
+func (a *LogLevels) elevatedLevel(name string, level zapcore.Level) zapcore.Level {
+	for _, elev := range a.elevations {
+		if elev.Level < level && a.doesKeyMatch(name, elev.Pattern) {
+			level = elev.Level
+		}
+	}
+
+	return level
+}
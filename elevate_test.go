@@ -0,0 +1,187 @@
+package zaptool_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func waitForLevels(t *testing.T, loglvls *zaptool.LogLevels, expect string) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if loglvls.String() == expect {
+			return
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Errorf("levels: got '%s', want '%s'", loglvls.String(), expect)
+}
+
+func TestLogLevels_ElevateLevel(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.Named("Server.Process", zapcore.WarnLevel)
+
+	if loglvls.ElevateLevel("Client", zapcore.DebugLevel, time.Minute) {
+		t.Error("ElevateLevel should return false when no existing levels match")
+	}
+
+	if elevations := loglvls.Elevations(); len(elevations) != 0 {
+		t.Errorf("ElevateLevel should not add an elevation when no existing levels match: %v", elevations)
+	}
+
+	if !loglvls.ElevateLevel("Server.*", zapcore.DebugLevel, time.Hour) {
+		t.Error("ElevateLevel should return true when existing levels match")
+	}
+
+	loglvls.ElevateLevel("Server.Process", zapcore.InfoLevel, time.Hour)
+
+	if loglvls.String() != "Internal.LogLevels:info,Server.Process:debug" {
+		t.Errorf("levels: got '%s'", loglvls.String())
+	}
+
+	if len(loglvls.Elevations()) != 2 {
+		t.Errorf("elevations: got %d, want 2", len(loglvls.Elevations()))
+	}
+
+	loglvls.Named("Server.Other")
+
+	if loglvls.String() != "Internal.LogLevels:info,Server.Other:debug,Server.Process:debug" {
+		t.Errorf("loggers created during an elevation should be elevated: %s", loglvls.String())
+	}
+
+	loglvls.SetLevel("Server.Process", zapcore.ErrorLevel)
+
+	if loglvls.String() != "Internal.LogLevels:info,Server.Other:debug,Server.Process:debug" {
+		t.Errorf("levels set during an elevation should stay elevated: %s", loglvls.String())
+	}
+
+	// the remaining elevation still applies to Server.Process.
+	loglvls.CancelElevations("Server.*")
+
+	if loglvls.String() != "Internal.LogLevels:info,Server.Other:info,Server.Process:info" {
+		t.Errorf("levels: got '%s'", loglvls.String())
+	}
+
+	loglvls.CancelElevations("Server.Process")
+
+	if loglvls.String() != "Internal.LogLevels:info,Server.Other:info,Server.Process:error" {
+		t.Errorf("levels should revert to the level set during the elevation: %s", loglvls.String())
+	}
+
+	// elevations are removed when they expire.
+	if !loglvls.ElevateLevel("Server.*", zapcore.DebugLevel, 10*time.Millisecond) {
+		t.Error("ElevateLevel should return true when existing levels match")
+	}
+
+	waitForLevels(t, loglvls, "Internal.LogLevels:info,Server.Other:info,Server.Process:error")
+
+	if len(loglvls.Elevations()) != 0 {
+		t.Errorf("elevations: got %d, want 0", len(loglvls.Elevations()))
+	}
+}
+
+func TestLogLevels_CancelElevations(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.Named("Server")
+	loglvls.Named("Server.Process")
+
+	loglvls.ElevateLevel("Server", "debug", time.Hour)
+
+	if loglvls.String() != "Internal.LogLevels:info,Server.Process:debug,Server:debug" {
+		t.Errorf("elevation should be inherited: %s", loglvls.String())
+	}
+
+	if !loglvls.CancelElevations("Server") {
+		t.Error("CancelElevations should return true when elevations were removed")
+	}
+
+	if loglvls.String() != "Internal.LogLevels:info,Server.Process:info,Server:info" {
+		t.Errorf("levels: got '%s'", loglvls.String())
+	}
+}
+
+func TestLogLevels_ElevateLevelInheritedSnapshot(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.Named("Server", zapcore.InfoLevel)
+	loglvls.Named("Server.Process")
+
+	loglvls.ElevateLevel("Server", "debug", time.Hour)
+	loglvls.Named("Server.Other")
+
+	if loglvls.String() != "Internal.LogLevels:info,Server.Other:debug,Server.Process:debug,Server:debug" {
+		t.Errorf("elevation should be inherited: %s", loglvls.String())
+	}
+
+	for _, item := range loglvls.Snapshot().Levels {
+		if item.Level != zapcore.InfoLevel {
+			t.Errorf("snapshot should not include elevations: %s is %s", item.Name, item.Level)
+		}
+	}
+
+	loglvls.SetLevel("Server", "warn")
+
+	if loglvls.String() != "Internal.LogLevels:info,Server.Other:debug,Server.Process:debug,Server:debug" {
+		t.Errorf("levels set during an elevation should stay elevated: %s", loglvls.String())
+	}
+
+	loglvls.CancelElevations("Server")
+
+	if loglvls.String() != "Internal.LogLevels:info,Server.Other:warn,Server.Process:warn,Server:warn" {
+		t.Errorf("levels should revert to the configured level: %s", loglvls.String())
+	}
+}
+
+func TestLogLevels_ElevateLevelAncestor(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.Named("Server.Process")
+
+	if !loglvls.ElevateLevel("Server", "debug", time.Hour) {
+		t.Error("ElevateLevel should match the levels that inherit from the name")
+	}
+
+	loglvls.Named("Server.Process.Worker")
+
+	if loglvls.String() != "Internal.LogLevels:info,Server.Process.Worker:debug,Server.Process:debug" {
+		t.Errorf("levels below the name should be elevated: %s", loglvls.String())
+	}
+
+	loglvls.SetLevel("Server", "warn")
+
+	if loglvls.String() != "Internal.LogLevels:info,Server.Process.Worker:debug,Server.Process:debug" {
+		t.Errorf("levels set during an elevation should stay elevated: %s", loglvls.String())
+	}
+
+	loglvls.CancelElevations("Server")
+
+	if loglvls.String() != "Internal.LogLevels:info,Server.Process.Worker:warn,Server.Process:warn" {
+		t.Errorf("levels should revert to the configured level: %s", loglvls.String())
+	}
+
+	if !loglvls.ElevateLevel("Server", "debug", time.Hour) {
+		t.Error("ElevateLevel should match the levels that inherit from a rule for the name")
+	}
+
+	if loglvls.String() != "Internal.LogLevels:info,Server.Process.Worker:debug,Server.Process:debug" {
+		t.Errorf("levels below the name should be elevated: %s", loglvls.String())
+	}
+}
@@ -0,0 +1,61 @@
+package zaptool
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// LevelEvent describes a change to a named level.
+type LevelEvent struct {
+	// Name is the name of the level that changed.
+	Name string
+	// Old is the level before the change.
+	Old zapcore.Level
+	// New is the level after the change, it is zapcore.InvalidLevel if the level was deleted.
+	New zapcore.Level
+	// Pattern is the name or wildcard pattern that caused the change.
+	Pattern string
+	// Deleted is true if the level was removed with DeleteLevel or reclaimed by ReclaimIdle.
+	Deleted bool
+	// Time is when the change was made.
+	Time time.Time
+}
+
+// Subscribe returns a channel that receives an event whenever a named level changes and a
+// function that unsubscribes and closes the channel.
+//
+// Events are delivered without blocking, if the channel buffer is full the event is
+// dropped for that subscriber.
+func (a *LogLevels) Subscribe(buffer int) (<-chan LevelEvent, func()) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	id := a.nextSub
+	a.nextSub++
+
+	ch := make(chan LevelEvent, buffer)
+	a.subs[id] = ch
+
+	return ch, func() {
+		a.lock.Lock()
+		defer a.lock.Unlock()
+
+		if sub, ok := a.subs[id]; ok {
+			delete(a.subs, id)
+			close(sub)
+		}
+	}
+}
+
+// publish delivers the event to every subscriber without blocking.
+//
+// Callers must hold the lock.
+func (a *LogLevels) publish(event LevelEvent) {
+	for _, sub := range a.subs {
+		select {
+		case sub <- event:
+		default:
+		}
+	}
+}
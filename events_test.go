@@ -0,0 +1,77 @@
+package zaptool_test
+
+import (
+	"testing"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogLevels_Subscribe(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.Named("Server")
+	loglvls.Named("Server.Process")
+
+	events, unsubscribe := loglvls.Subscribe(10)
+
+	loglvls.SetLevel("Server", zapcore.DebugLevel)
+	loglvls.SetLevel("Server", zapcore.DebugLevel)
+	loglvls.DeleteLevel("Server.Process")
+
+	expect := []zaptool.LevelEvent{
+		{Name: "Server", Old: zapcore.InfoLevel, New: zapcore.DebugLevel, Pattern: "Server"},
+		{Name: "Server.Process", Old: zapcore.InfoLevel, New: zapcore.DebugLevel, Pattern: "Server"},
+		{Name: "Server.Process", Old: zapcore.DebugLevel, New: zapcore.InvalidLevel, Pattern: "Server.Process", Deleted: true},
+	}
+
+	for _, want := range expect {
+		got := <-events
+		if got.Time.IsZero() {
+			t.Errorf("event time should be set: %+v", got)
+		}
+
+		got.Time = want.Time
+		if got != want {
+			t.Errorf("event: got %+v, want %+v", got, want)
+		}
+	}
+
+	unsubscribe()
+	unsubscribe()
+
+	loglvls.SetLevel("Server", zapcore.InfoLevel)
+
+	if _, ok := <-events; ok {
+		t.Error("channel should be closed after unsubscribe")
+	}
+}
+
+func TestLogLevels_SubscribeDoesNotBlock(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.Named("Server")
+
+	events, unsubscribe := loglvls.Subscribe(1)
+	defer unsubscribe()
+
+	loglvls.SetLevel("Server", zapcore.DebugLevel)
+	loglvls.SetLevel("Server", zapcore.WarnLevel)
+	loglvls.SetLevel("Server", zapcore.ErrorLevel)
+
+	if got := <-events; got.New != zapcore.DebugLevel {
+		t.Errorf("first event should be delivered, got %+v", got)
+	}
+
+	select {
+	case got := <-events:
+		t.Errorf("events should be dropped when the buffer is full, got %+v", got)
+	default:
+	}
+}
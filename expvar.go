@@ -0,0 +1,56 @@
+package zaptool
+
+import (
+	"expvar"
+
+	"go.uber.org/zap"
+)
+
+// expvarValue is the value published by ExpvarFunc.
+type expvarValue struct {
+	Levels    map[string]string      `json:"levels"`
+	Stats     map[string]LoggerStats `json:"stats,omitempty"`
+	Reclaimed *uint64                `json:"reclaimed,omitempty"`
+}
+
+// ExpvarFunc returns an expvar.Func that reports the current levels of the LogManager (and
+// the entry counters and number of reclaimed levels if it supports them, eg. LogLevels)
+// each time it is read.
+//
+//	{"levels":{"Server.Process":"debug"},"stats":{"Server.Process":{"written":{"info":2},...}}}
+func ExpvarFunc(logmgr LogManager) expvar.Func {
+	return func() interface{} {
+		out := expvarValue{
+			Levels: map[string]string{},
+		}
+
+		_ = logmgr.Iterator(func(name string, lvl *zap.AtomicLevel) error {
+			out.Levels[name] = levelString(lvl.Level())
+			return nil
+		})
+
+		if si, ok := logmgr.(statsIterator); ok {
+			out.Stats = map[string]LoggerStats{}
+
+			_ = si.StatsIterator(func(s LoggerStats) error {
+				out.Stats[s.Name] = s
+				return nil
+			})
+		}
+
+		if r, ok := logmgr.(reclaimCounter); ok {
+			reclaimed := r.Reclaimed()
+			out.Reclaimed = &reclaimed
+		}
+
+		return out
+	}
+}
+
+// PublishExpvar publishes the levels of the LogManager (see ExpvarFunc) as the expvar
+// variable name, so they are included in /debug/vars.
+//
+// Like expvar.Publish it panics if a variable with the name is already published.
+func PublishExpvar(name string, logmgr LogManager) {
+	expvar.Publish(name, ExpvarFunc(logmgr))
+}
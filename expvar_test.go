@@ -0,0 +1,87 @@
+package zaptool_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type expvarTestValue struct {
+	Levels map[string]string `json:"levels"`
+	Stats  map[string]struct {
+		Written map[string]uint64 `json:"written"`
+		Dropped map[string]uint64 `json:"dropped"`
+	} `json:"stats"`
+}
+
+func TestPublishExpvar(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.Named("Server.Process")
+
+	// the expvar registry is global, so the name must be unique when run with -count.
+	name := fmt.Sprintf("zaptool_test_levels_%d", time.Now().UnixNano())
+	zaptool.PublishExpvar(name, loglvls)
+
+	v := expvar.Get(name)
+	if v == nil {
+		t.Fatal("expvar variable should be published")
+	}
+
+	if v.String() != zaptool.ExpvarFunc(loglvls).String() {
+		t.Errorf("published value: got '%s'", v.String())
+	}
+}
+
+func TestExpvarFunc(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	named := loglvls.Named("Server.Process")
+
+	v := zaptool.ExpvarFunc(loglvls)
+
+	named.Info("written")
+	named.Debug("dropped")
+	loglvls.SetLevel("Server.*", zapcore.WarnLevel)
+
+	var out expvarTestValue
+	if err := json.Unmarshal([]byte(v.String()), &out); err != nil {
+		t.Fatalf("unable to decode expvar value %s: %s", v.String(), err)
+	}
+
+	if out.Levels["Server.Process"] != "warn" || out.Levels["Internal.LogLevels"] != "info" {
+		t.Errorf("levels should be read when the variable is read: %v", out.Levels)
+	}
+
+	stats, ok := out.Stats["Server.Process"]
+	if !ok {
+		t.Fatalf("stats should be included for a LogLevels: %s", v.String())
+	}
+
+	if stats.Written["info"] != 1 || stats.Dropped["debug"] != 1 {
+		t.Errorf("stats: got %+v", stats)
+	}
+}
+
+func TestExpvarFunc_SubLogLevels(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	sublvls := zaptool.NewSubLogLevels("Server", zaptool.NewLogLevels(logger))
+	sublvls.Named("Process")
+
+	if out := zaptool.ExpvarFunc(sublvls).String(); out != `{"levels":{"Process":"info"}}` {
+		t.Errorf("expvar value: got '%s'", out)
+	}
+}
@@ -0,0 +1,142 @@
+package zaptool
+
+import (
+	"math"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// globalLevelUnset is stored when a global level is not set.
+const globalLevelUnset = math.MaxInt32
+
+// globalLevels holds the floor and override levels shared by every logger created by a
+// LogLevels, they are combined with each named level without changing it.
+type globalLevels struct {
+	floor    atomic.Int32
+	override atomic.Int32
+}
+
+// newGlobalLevels returns a globalLevels with the floor and override unset.
+func newGlobalLevels() *globalLevels {
+	out := &globalLevels{}
+	out.floor.Store(globalLevelUnset)
+	out.override.Store(globalLevelUnset)
+
+	return out
+}
+
+// minLevel returns the minimum enabled level for a logger with the named level lvl, the
+// override replaces the named level and the floor raises it.
+func (g *globalLevels) minLevel(lvl zapcore.Level) zapcore.Level {
+	if g == nil {
+		return lvl
+	}
+
+	if v := g.override.Load(); v != globalLevelUnset {
+		lvl = zapcore.Level(v)
+	}
+
+	if v := g.floor.Load(); v != globalLevelUnset && zapcore.Level(v) > lvl {
+		lvl = zapcore.Level(v)
+	}
+
+	return lvl
+}
+
+// loadLevel returns the level stored in v and true if it is set.
+func loadLevel(v *atomic.Int32) (zapcore.Level, bool) {
+	lvl := v.Load()
+	if lvl == globalLevelUnset {
+		return zapcore.InvalidLevel, false
+	}
+
+	return zapcore.Level(lvl), true
+}
+
+// SetLevelFloor sets a minimum level for every logger, entries below the floor are not
+// written regardless of the named level, the named levels are not changed.
+func (a *LogLevels) SetLevelFloor(lvl interface{}) bool {
+	return a.SetLevelFloorBy(lvl, ChangeSource{})
+}
+
+// SetLevelFloorBy is SetLevelFloor with the source of the change recorded in the history
+// (and audit logger if there is one).
+func (a *LogLevels) SetLevelFloorBy(lvl interface{}, src ChangeSource) bool {
+	level, ok := parseLevel(lvl)
+	if !ok {
+		return false
+	}
+
+	a.global.floor.Store(int32(level))
+	a.iLogger.Info("level floor set", zap.String("level", level.String()))
+
+	a.record(LevelChange{Action: LevelChangeSetFloor, Level: level.String(), Matched: true, Source: src})
+
+	return true
+}
+
+// ClearLevelFloor removes the level floor, loggers go back to their named level.
+func (a *LogLevels) ClearLevelFloor() {
+	a.ClearLevelFloorBy(ChangeSource{})
+}
+
+// ClearLevelFloorBy is ClearLevelFloor with the source of the change recorded in the
+// history (and audit logger if there is one).
+func (a *LogLevels) ClearLevelFloorBy(src ChangeSource) {
+	_, found := loadLevel(&a.global.floor)
+
+	a.global.floor.Store(globalLevelUnset)
+	a.iLogger.Info("level floor cleared")
+
+	a.record(LevelChange{Action: LevelChangeClearFloor, Matched: found, Source: src})
+}
+
+// LevelFloor returns the level floor and true if it is set.
+func (a *LogLevels) LevelFloor() (zapcore.Level, bool) {
+	return loadLevel(&a.global.floor)
+}
+
+// SetLevelOverride sets the level used by every logger in place of its named level, the
+// named levels are not changed. If a level floor is also set, the floor still applies.
+func (a *LogLevels) SetLevelOverride(lvl interface{}) bool {
+	return a.SetLevelOverrideBy(lvl, ChangeSource{})
+}
+
+// SetLevelOverrideBy is SetLevelOverride with the source of the change recorded in the
+// history (and audit logger if there is one).
+func (a *LogLevels) SetLevelOverrideBy(lvl interface{}, src ChangeSource) bool {
+	level, ok := parseLevel(lvl)
+	if !ok {
+		return false
+	}
+
+	a.global.override.Store(int32(level))
+	a.iLogger.Info("level override set", zap.String("level", level.String()))
+
+	a.record(LevelChange{Action: LevelChangeSetOverride, Level: level.String(), Matched: true, Source: src})
+
+	return true
+}
+
+// ClearLevelOverride removes the level override, loggers go back to their named level.
+func (a *LogLevels) ClearLevelOverride() {
+	a.ClearLevelOverrideBy(ChangeSource{})
+}
+
+// ClearLevelOverrideBy is ClearLevelOverride with the source of the change recorded in the
+// history (and audit logger if there is one).
+func (a *LogLevels) ClearLevelOverrideBy(src ChangeSource) {
+	_, found := loadLevel(&a.global.override)
+
+	a.global.override.Store(globalLevelUnset)
+	a.iLogger.Info("level override cleared")
+
+	a.record(LevelChange{Action: LevelChangeClearOverride, Matched: found, Source: src})
+}
+
+// LevelOverride returns the level override and true if it is set.
+func (a *LogLevels) LevelOverride() (zapcore.Level, bool) {
+	return loadLevel(&a.global.override)
+}
@@ -0,0 +1,105 @@
+package zaptool_test
+
+import (
+	"testing"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogLevels_LevelFloor(t *testing.T) {
+	fac, observedLogs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	debugLogger := loglvls.Named("Server.Process", zapcore.DebugLevel)
+	errorLogger := loglvls.Named("Client", zapcore.ErrorLevel)
+
+	if loglvls.SetLevelFloor("loud") {
+		t.Error("SetLevelFloor should return false for an invalid level")
+	}
+
+	loglvls.SetLevelFloor(zapcore.WarnLevel)
+
+	if lvl, ok := loglvls.LevelFloor(); !ok || lvl != zapcore.WarnLevel {
+		t.Errorf("LevelFloor: got %s, %t", lvl, ok)
+	}
+
+	observedLogs.TakeAll()
+
+	debugLogger.Info("should not log")
+	debugLogger.Warn("should log")
+	errorLogger.Warn("should not log")
+	errorLogger.Error("should log")
+
+	if loglvls.String() != "Client:error,Internal.LogLevels:info,Server.Process:debug" {
+		t.Errorf("named levels should not change: %s", loglvls.String())
+	}
+
+	loglvls.ClearLevelFloor()
+
+	debugLogger.With(zap.String("key", "value")).Debug("should log")
+
+	logs := observedLogs.Filter(func(le observer.LoggedEntry) bool {
+		return le.LoggerName != "Internal.LogLevels"
+	}).All()
+	if len(logs) != 3 {
+		t.Fatalf("should contain 3 log messages, instead contained %d messages", len(logs))
+	}
+
+	for _, le := range logs {
+		if le.Message != "should log" {
+			t.Errorf("this message should not have been logged: %s", le.Message)
+		}
+	}
+}
+
+func TestLogLevels_LevelOverride(t *testing.T) {
+	fac, observedLogs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	errorLogger := loglvls.Named("Client", zapcore.ErrorLevel)
+
+	loglvls.SetLevelOverride(zapcore.DebugLevel)
+
+	if _, ok := loglvls.LevelOverride(); !ok {
+		t.Error("LevelOverride should be set")
+	}
+
+	observedLogs.TakeAll()
+
+	errorLogger.Debug("should log")
+
+	loglvls.SetLevelFloor(zapcore.InfoLevel)
+	errorLogger.Debug("should not log")
+	errorLogger.Info("should log")
+
+	loglvls.ClearLevelFloor()
+	loglvls.ClearLevelOverride()
+
+	if _, ok := loglvls.LevelOverride(); ok {
+		t.Error("LevelOverride should not be set")
+	}
+
+	errorLogger.Warn("should not log")
+
+	logs := observedLogs.Filter(func(le observer.LoggedEntry) bool {
+		return le.LoggerName != "Internal.LogLevels"
+	}).All()
+	if len(logs) != 2 {
+		t.Fatalf("should contain 2 log messages, instead contained %d messages", len(logs))
+	}
+
+	for _, le := range logs {
+		if le.Message != "should log" {
+			t.Errorf("this message should not have been logged: %s", le.Message)
+		}
+	}
+
+	if loglvls.String() != "Client:error,Internal.LogLevels:info" {
+		t.Errorf("named levels should not change: %s", loglvls.String())
+	}
+}
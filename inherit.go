@@ -0,0 +1,270 @@
+package zaptool
+
+import (
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// levelEntry is a named level stored in LogLevels.
+//
+// Entries that have not been explicitly set (by SetLevel, a rule or a level
+// passed to Named) inherit the level of their nearest dot-separated ancestor.
+type levelEntry struct {
+	level      *zap.AtomicLevel
+	configured zapcore.Level
+	def        zapcore.Level
+	explicit   bool
+	stats      *loggerStats
+	sampler    *entrySampler
+	pinned     bool
+	lastSeen   time.Time
+	seen       atomic.Bool
+	reclaimed  atomic.Bool
+}
+
+// newLevelEntry returns an entry with the default level, the caller sets the level.
+func newLevelEntry(def zapcore.Level) *levelEntry {
+	return &levelEntry{
+		configured: def,
+		def:        def,
+		stats:      newLoggerStats(),
+		sampler:    &entrySampler{},
+		lastSeen:   time.Now(),
+	}
+}
+
+// parentName returns the name with the last dot-separated segment removed.
+func parentName(name string) (string, bool) {
+	idx := strings.LastIndex(name, ".")
+	if idx <= 0 {
+		return "", false
+	}
+
+	return name[:idx], true
+}
+
+// inheritedLevel returns the configured level of the nearest ancestor of name, an
+// ancestor is either an existing level or a name matched by a rule.
+//
+// Elevations of the ancestor are not inherited as part of the configured level, they are
+// applied to the effective level (see effectiveLevel).
+//
+// Callers must hold the lock.
+func (a *LogLevels) inheritedLevel(name string) (zapcore.Level, bool) {
+	for parent, ok := parentName(name); ok; parent, ok = parentName(parent) {
+		if v, found := a.levels[parent]; found {
+			return v.configured, true
+		}
+
+		if level, found := a.ruleLevel(parent); found {
+			return level, true
+		}
+	}
+
+	return zapcore.InfoLevel, false
+}
+
+// matchesEntry returns true if the pattern matches the name or, if the level is not
+// explicitly set, one of the ancestors it inherits from (so setting a level for the
+// pattern changes it).
+//
+// Callers must hold the lock.
+func (a *LogLevels) matchesEntry(name string, entry *levelEntry, pattern string) bool {
+	if a.doesKeyMatch(name, pattern) {
+		return true
+	}
+
+	if entry.explicit {
+		return false
+	}
+
+	for parent, ok := parentName(name); ok; parent, ok = parentName(parent) {
+		if a.doesKeyMatch(parent, pattern) {
+			return true
+		}
+
+		if _, found := a.levels[parent]; found {
+			return false
+		}
+
+		if _, found := a.ruleLevel(parent); found {
+			return false
+		}
+	}
+
+	return false
+}
+
+// anyMatch returns true if the pattern matches any level or an ancestor it inherits from
+// (see matchesEntry).
+//
+// Callers must hold the lock.
+func (a *LogLevels) anyMatch(pattern string) bool {
+	for name, entry := range a.levels {
+		if a.matchesEntry(name, entry, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasMatch returns true if setting a level for the name (or pattern) would match any
+// levels, as reported by SetLevel.
+func (a *LogLevels) hasMatch(pattern string) bool {
+	pattern = a.normaliseName(pattern)
+
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	return a.anyMatch(pattern)
+}
+
+// addEntry stores the entry for the name and counts it as a child of each of its ancestors.
+//
+// Callers must hold the lock.
+func (a *LogLevels) addEntry(name string, entry *levelEntry) {
+	a.levels[name] = entry
+
+	for parent, ok := parentName(name); ok; parent, ok = parentName(parent) {
+		a.children[parent]++
+	}
+}
+
+// removeEntry removes the entry for the name and from the count of each of its ancestors.
+//
+// Callers must hold the lock.
+func (a *LogLevels) removeEntry(name string) {
+	delete(a.levels, name)
+
+	for parent, ok := parentName(name); ok; parent, ok = parentName(parent) {
+		if a.children[parent] <= 1 {
+			delete(a.children, parent)
+			continue
+		}
+
+		a.children[parent]--
+	}
+}
+
+// inherit updates every level that is not explicitly set and has an ancestor to
+// the level of its nearest ancestor, parents are resolved before their children.
+// It returns true if any level was changed.
+//
+// Callers must hold the lock.
+func (a *LogLevels) inherit(cause string) bool {
+	return a.inheritWhere(cause, func(string) bool { return true })
+}
+
+// inheritFrom is inherit for the level of the name and the levels below it, when a level is
+// added or changed only the levels below it can inherit from it.
+//
+// Callers must hold the lock.
+func (a *LogLevels) inheritFrom(name, cause string) bool {
+	if a.children[name] == 0 {
+		return a.inheritWhere(cause, nil, name)
+	}
+
+	prefix := name + "."
+
+	return a.inheritWhere(cause, func(key string) bool {
+		return key == name || strings.HasPrefix(key, prefix)
+	})
+}
+
+// inheritMatching is inherit for the levels that match the pattern or have an ancestor that
+// does, which are the only levels that can inherit a different level when the levels or
+// rules matching the pattern change.
+//
+// Callers must hold the lock.
+func (a *LogLevels) inheritMatching(pattern, cause string) bool {
+	m, err := a.matchers.get(pattern)
+	if err != nil {
+		return false
+	}
+
+	return a.inheritWhere(cause, func(key string) bool {
+		for check, ok := key, true; ok; check, ok = parentName(check) {
+			if m.match(check) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// inheritWhere is inherit for the levels accepted by the filter, or only the names supplied
+// if there is no filter.
+//
+// Callers must hold the lock.
+func (a *LogLevels) inheritWhere(cause string, filter func(string) bool, names ...string) bool {
+	if filter != nil {
+		for k, v := range a.levels {
+			if !v.explicit && filter(k) {
+				names = append(names, k)
+			}
+		}
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return strings.Count(names[i], ".") < strings.Count(names[j], ".")
+	})
+
+	changed := false
+
+	for _, name := range names {
+		entry, exists := a.levels[name]
+		if !exists || entry.explicit {
+			continue
+		}
+
+		level, ok := a.inheritedLevel(name)
+		if !ok {
+			continue
+		}
+
+		// the effective level also changes when an elevation of the ancestor does.
+		if entry.configured != level || entry.level.Level() != a.effectiveLevel(name, level, false) {
+			a.setEntryLevel(name, entry, level, cause)
+
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// resetEntry clears the explicit level of the entry, it takes the level of a matching rule
+// if there is one, otherwise it is left to inherit (or given its default level if it has
+// no ancestor).
+//
+// Callers must hold the lock and call inherit afterwards.
+func (a *LogLevels) resetEntry(name string, entry *levelEntry, cause string) {
+	entry.explicit = false
+
+	if level, ok := a.ruleLevel(name); ok {
+		entry.explicit = true
+		a.setEntryLevel(name, entry, level, cause)
+	} else if _, inherited := a.inheritedLevel(name); !inherited {
+		a.setEntryLevel(name, entry, entry.def, cause)
+	}
+}
+
+// setExplicitLevel sets the level for an existing name and marks it as explicitly set.
+func (a *LogLevels) setExplicitLevel(name string, level zapcore.Level) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if v, ok := a.levels[name]; ok {
+		v.explicit = true
+		a.setEntryLevel(name, v, level, name)
+
+		a.inheritFrom(name, name)
+	}
+}
@@ -0,0 +1,259 @@
+package zaptool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// levelsHandlerMaxBodySize is the maximum size of a request body accepted by the levels handler.
+const levelsHandlerMaxBodySize = 64 * 1024
+
+var (
+	// ErrUnknownLevel is returned when a level can not be parsed.
+	ErrUnknownLevel = errors.New("unknown level")
+
+	// ErrNoMatchingLoggers is returned when a name or pattern does not match any loggers.
+	ErrNoMatchingLoggers = errors.New("no loggers match name")
+
+	// errMatchFound stops iterating once a matching level is found.
+	errMatchFound = errors.New("match found")
+)
+
+// LevelItem is a named level as returned by the levels handler.
+type LevelItem struct {
+	Name  string `json:"name"`
+	Level string `json:"level"`
+}
+
+// levelsRequest is the body of a request to set a level.
+type levelsRequest struct {
+	Name   string `json:"name"`
+	Level  string `json:"level"`
+	Reason string `json:"reason,omitempty"`
+	For    string `json:"for,omitempty"`
+}
+
+// levelsResponse is the body returned by the levels handler on success.
+type levelsResponse struct {
+	Levels []LevelItem `json:"levels"`
+}
+
+// levelsErrorResponse is the body returned by the levels handler on error.
+type levelsErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// levelsHandler is the http.Handler implementation for LevelsHTTPHandler.
+type levelsHandler struct {
+	logmgr LogManager
+	opts   *levelsHandlerOptions
+}
+
+// ServeHTTP lists the levels on GET and sets a level on PUT or POST.
+func (h levelsHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		h.list(w)
+	case http.MethodPut, http.MethodPost:
+		if !allowChange(w, req, h.opts) {
+			return
+		}
+
+		h.set(w, req)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT")
+		writeLevelsError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+	}
+}
+
+// list writes all the named levels sorted by name.
+func (h levelsHandler) list(w http.ResponseWriter) {
+	out := levelsResponse{Levels: []LevelItem{}}
+
+	if err := h.logmgr.Iterator(func(name string, lvl *zap.AtomicLevel) error {
+		out.Levels = append(out.Levels, LevelItem{Name: name, Level: levelString(lvl.Level())})
+		return nil
+	}); err != nil {
+		writeLevelsError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	sort.Slice(out.Levels, func(i, j int) bool {
+		return out.Levels[i].Name < out.Levels[j].Name
+	})
+
+	writeLevelsJSON(w, http.StatusOK, out)
+}
+
+// set decodes a levelsRequest from the request body and sets the level.
+func (h levelsHandler) set(w http.ResponseWriter, req *http.Request) {
+	var item levelsRequest
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, req.Body, levelsHandlerMaxBodySize))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&item); err != nil {
+		writeLevelsError(w, http.StatusBadRequest, fmt.Errorf("unable to decode request: %w", err))
+		return
+	}
+
+	if item.Name == "" {
+		writeLevelsError(w, http.StatusBadRequest, errors.New("name must be specified"))
+		return
+	}
+
+	if err := ValidatePattern(item.Name); err != nil {
+		writeLevelsError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, ok := parseLevel(item.Level); !ok {
+		writeLevelsError(w, http.StatusBadRequest, fmt.Errorf("%w: %q", ErrUnknownLevel, item.Level))
+		return
+	}
+
+	var d time.Duration
+
+	if item.For != "" {
+		var err error
+
+		if d, err = time.ParseDuration(item.For); err != nil || d <= 0 {
+			writeLevelsError(w, http.StatusBadRequest, fmt.Errorf("invalid duration: %q", item.For))
+			return
+		}
+	}
+
+	if !hasMatch(h.logmgr, item.Name) {
+		writeLevelsError(w, http.StatusNotFound, fmt.Errorf("%w: %q", ErrNoMatchingLoggers, item.Name))
+		return
+	}
+
+	src := ChangeSource{
+		Reason: item.Reason,
+		Origin: "http " + req.RemoteAddr,
+	}
+
+	if h.opts.actor != nil {
+		src.Actor = h.opts.actor(req)
+	}
+
+	if d > 0 {
+		h.elevate(w, item, d, src)
+		return
+	}
+
+	if !setLevelBy(h.logmgr, item.Name, item.Level, src) {
+		writeLevelsError(w, http.StatusNotFound, fmt.Errorf("%w: %q", ErrNoMatchingLoggers, item.Name))
+		return
+	}
+
+	h.list(w)
+}
+
+// elevate raises the level for the duration if the LogManager supports elevating levels
+// (eg. LogLevels).
+func (h levelsHandler) elevate(w http.ResponseWriter, item levelsRequest, d time.Duration, src ChangeSource) {
+	found, supported := elevateLevelBy(h.logmgr, item.Name, item.Level, d, src)
+
+	switch {
+	case !supported:
+		writeLevelsError(w, http.StatusBadRequest, errors.New("setting a level for a duration is not supported"))
+	case !found:
+		writeLevelsError(w, http.StatusNotFound, fmt.Errorf("%w: %q", ErrNoMatchingLoggers, item.Name))
+	default:
+		h.list(w)
+	}
+}
+
+// allowChange returns true if the request may change levels, otherwise it writes the error.
+//
+// Requests must be JSON so a browser can not send them from another site (eg. with a form)
+// without a CORS preflight check, and must be allowed by the authorizer if there is one.
+func allowChange(w http.ResponseWriter, req *http.Request, opts *levelsHandlerOptions) bool {
+	if mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		writeLevelsError(w, http.StatusUnsupportedMediaType, errors.New("content type must be application/json"))
+		return false
+	}
+
+	if opts.authorizer != nil && !opts.authorizer(req) {
+		writeLevelsError(w, http.StatusForbidden, errors.New("forbidden"))
+		return false
+	}
+
+	return true
+}
+
+// hasMatch returns true if the name (or pattern) matches any existing level or one of its
+// dot-separated ancestors, it is checked before a level is set so a request that is
+// rejected does not leave a rule behind.
+func hasMatch(logmgr LogManager, name string) bool {
+	if m, ok := logmgr.(interface{ hasMatch(pattern string) bool }); ok {
+		return m.hasMatch(name)
+	}
+
+	matcher, err := compilePattern(name, false)
+	if err != nil {
+		return false
+	}
+
+	err = logmgr.Iterator(func(key string, _ *zap.AtomicLevel) error {
+		for check, ok := key, true; ok; check, ok = parentName(check) {
+			if matcher.match(check) {
+				return errMatchFound
+			}
+		}
+
+		return nil
+	})
+
+	return errors.Is(err, errMatchFound)
+}
+
+// writeLevelsJSON writes the value as a JSON body with the status code.
+func writeLevelsJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeLevelsError writes the error as a JSON body with the status code.
+func writeLevelsError(w http.ResponseWriter, status int, err error) {
+	writeLevelsJSON(w, status, levelsErrorResponse{Error: err.Error()})
+}
+
+// LevelsHTTPHandler returns a http.Handler that lists the levels of the LogManager
+// as JSON on GET and sets a level on PUT or POST.
+//
+// The body of a PUT or POST is a JSON object with the name (or wildcard pattern)
+// and level to set, eg. `{"name":"Server.*","level":"debug"}`, and optionally the
+// reason for the change which is recorded (with the actor) if the LogManager keeps
+// a history of changes. If a duration is included (eg. `"for":"10m"`) the level is
+// only raised for the duration (see LogLevels.ElevateLevel).
+//
+// Requests that change levels must have a Content-Type of application/json, if no
+// authorizer is set (see LevelsHandlerOptionAuthorizer) anyone that can reach the handler
+// can change levels.
+func LevelsHTTPHandler(logmgr LogManager, opts ...levelsHandlerOptionsFunc) http.Handler {
+	opt := &levelsHandlerOptions{
+		authorizer: nil,
+		actor:      nil,
+	}
+
+	for _, f := range opts {
+		f(opt)
+	}
+
+	return levelsHandler{
+		logmgr,
+		opt,
+	}
+}
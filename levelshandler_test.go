@@ -0,0 +1,242 @@
+//nolint:gocritic,lll,depguard // Example code, ignore output comment line and log.Fatal.
+package zaptool_test
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func ExampleLevelsHTTPHandler() {
+	logger := zap.NewExample()
+	defer func() { _ = logger.Sync() }()
+
+	ll := zaptool.NewLogLevels(logger, zapcore.InfoLevel)
+	ll.Named("Server.Process")
+
+	ts := httptest.NewServer(zaptool.LevelsHTTPHandler(ll))
+	defer ts.Close()
+
+	res, err := http.Post(ts.URL, "application/json", strings.NewReader(`{"name":"Server.*","level":"warn"}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	defer res.Body.Close()
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("%s", body)
+	// Output:
+	// {"levels":[{"name":"Internal.LogLevels","level":"info"},{"name":"Server.Process","level":"warn"}]}
+}
+
+func TestLevelsHTTPHandler_Errors(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	ll := zaptool.NewLogLevels(logger)
+	ll.Named("Server.Process")
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		status int
+		error  string
+	}{
+		{"list", http.MethodGet, "", http.StatusOK, ""},
+		{"set", http.MethodPut, `{"name":"Server.Process","level":"debug"}`, http.StatusOK, ""},
+		{"unknown level", http.MethodPut, `{"name":"Server.Process","level":"loud"}`, http.StatusBadRequest, `unknown level: \"loud\"`},
+		{"no match", http.MethodPut, `{"name":"Client.*","level":"debug"}`, http.StatusNotFound, `no loggers match name: \"Client.*\"`},
+		{"missing name", http.MethodPost, `{"level":"debug"}`, http.StatusBadRequest, "name must be specified"},
+		{"invalid body", http.MethodPost, `{"name":`, http.StatusBadRequest, "unable to decode request"},
+		{"method", http.MethodPatch, "", http.StatusMethodNotAllowed, "method PATCH not allowed"},
+		{"invalid duration", http.MethodPut, `{"name":"Server.Process","level":"debug","for":"soon"}`, http.StatusBadRequest, `invalid duration: \"soon\"`},
+		{"no match for duration", http.MethodPut, `{"name":"Client.*","level":"debug","for":"1m"}`, http.StatusNotFound, `no loggers match name: \"Client.*\"`},
+	}
+
+	handler := zaptool.LevelsHTTPHandler(ll)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status: got %d, want %d", rec.Code, tt.status)
+			}
+
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("content type: got '%s', want 'application/json'", ct)
+			}
+
+			if tt.error != "" && !strings.Contains(rec.Body.String(), `"error":"`+tt.error) {
+				t.Errorf("body: got '%s', want error '%s'", rec.Body.String(), tt.error)
+			}
+		})
+	}
+
+	if ll.String() != "Internal.LogLevels:info,Server.Process:debug" {
+		t.Errorf("levels: got '%s'", ll.String())
+	}
+
+	if rules := ll.Rules(); len(rules) != 1 || rules[0].String() != "Server.Process:debug" {
+		t.Errorf("rejected requests should not store rules: %v", rules)
+	}
+
+	if elevations := ll.Elevations(); len(elevations) != 0 {
+		t.Errorf("rejected requests should not elevate levels: %v", elevations)
+	}
+}
+
+func TestLevelsHTTPHandler_Authorizer(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	ll := zaptool.NewLogLevels(logger)
+	ll.Named("Server.Process")
+
+	handler := zaptool.LevelsHTTPHandler(ll, zaptool.LevelsHandlerOptionAuthorizer(func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "Bearer secret"
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET should not require authorisation, got status %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"name":"Server.Process","level":"debug"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status: got %d, want %d", rec.Code, http.StatusForbidden)
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"name":"Server.Process","level":"debug"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	if ll.String() != "Internal.LogLevels:info,Server.Process:debug" {
+		t.Errorf("levels: got '%s'", ll.String())
+	}
+}
+
+func TestLevelsHTTPHandler_SubLogLevels(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	ll := zaptool.NewLogLevels(logger)
+	ll.Named("Server.Process")
+
+	handler := zaptool.LevelsHTTPHandler(zaptool.NewSubLogLevels("Server", ll))
+
+	for body, status := range map[string]int{
+		`{"name":"Nope.*","level":"debug"}`:  http.StatusNotFound,
+		`{"name":"Process","level":"debug"}`: http.StatusOK,
+	} {
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != status {
+			t.Errorf("%s: got %d, want %d", body, rec.Code, status)
+		}
+	}
+
+	if rules := ll.Rules(); len(rules) != 1 || rules[0].String() != "Server.Process:debug" {
+		t.Errorf("only the matching request should store a rule: %v", rules)
+	}
+}
+
+func TestLevelsHTTPHandler_Ancestor(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	ll := zaptool.NewLogLevels(logger)
+	ll.Named("Server.Process")
+	ll.Named("Server.Process.Worker")
+
+	handler := zaptool.LevelsHTTPHandler(ll)
+
+	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"name":"Server","level":"debug"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("setting the level of an ancestor should match its children, got status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if ll.String() != "Internal.LogLevels:info,Server.Process.Worker:debug,Server.Process:debug" {
+		t.Errorf("levels: got '%s'", ll.String())
+	}
+}
+
+func TestLevelsHTTPHandler_ContentType(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	ll := zaptool.NewLogLevels(logger)
+	ll.Named("Server.Process")
+
+	handler := zaptool.LevelsHTTPHandler(ll)
+
+	for _, contentType := range []string{"", "text/plain", "application/x-www-form-urlencoded"} {
+		// the body a cross-site form with enctype="text/plain" can send.
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"*","level":"debug","reason":"="}`))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusUnsupportedMediaType {
+			t.Errorf("content type '%s': got %d, want %d", contentType, rec.Code, http.StatusUnsupportedMediaType)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"*","level":"debug"}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("content type with parameters: got %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	if rules := ll.Rules(); len(rules) != 1 {
+		t.Errorf("only the JSON request should change levels: %v", rules)
+	}
+}
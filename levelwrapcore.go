@@ -1,53 +1,113 @@
 package zaptool
 
 import (
-	"go.uber.org/zap"
+	"errors"
+
 	"go.uber.org/zap/zapcore"
 )
 
 type levelWrapCore struct {
-	lvl zap.AtomicLevel
-	c   zapcore.Core
+	ref    *entryRef
+	global *globalLevels
+	c      zapcore.Core
+	routes []zapcore.Core
 }
 
-// Enabled returns true if the given level is at or above this level.
+// Enabled returns true if the given level is at or above this level (after the
+// global floor and override are applied).
+//
+// A *zap.Logger checks Enabled before building an entry, so an entry below the level
+// is counted as dropped here.
 func (c *levelWrapCore) Enabled(lvl zapcore.Level) bool {
-	return lvl >= c.lvl.Level()
+	if lvl >= c.Level() {
+		return true
+	}
+
+	c.ref.get().stats.drop(lvl)
+
+	return false
+}
+
+// Level returns the minimum enabled level, it is used by zapcore.LevelOf so that
+// finding the level does not count entries as dropped.
+func (c *levelWrapCore) Level() zapcore.Level {
+	return c.global.minLevel(c.ref.get().level.Level())
 }
 
-// With adds structured context to the Core.
+// With adds structured context to the Core, the returned Core shares the level
+// so loggers derived with With keep following it.
 func (c *levelWrapCore) With(fields []zapcore.Field) zapcore.Core {
-	return c.c.With(fields)
+	var routes []zapcore.Core
+	for _, route := range c.routes {
+		routes = append(routes, route.With(fields))
+	}
+
+	return &levelWrapCore{
+		ref:    c.ref,
+		global: c.global,
+		c:      c.c.With(fields),
+		routes: routes,
+	}
 }
 
 // Check determines whether the supplied Entry should be logged (using the
-// embedded LevelEnabler and possibly some extra logic). If the entry
+// embedded LevelEnabler and the sampling policy of the name). If the entry
 // should be logged, the Core adds itself to the CheckedEntry and returns
 // the result.
 //
 // Callers must use Check before calling Write.
 func (c *levelWrapCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
-	if c.lvl.Enabled(ent.Level) {
-		return ce.AddCore(ent, c.c)
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+
+	if entry := c.ref.get(); !entry.sampler.sample(ent) {
+		entry.stats.sample(ent.Level)
+		return ce
 	}
 
-	return ce
+	return ce.AddCore(ent, c)
 }
 
 // Write serializes the Entry and any Fields supplied at the log site and
 // writes them to their destination.
 //
 // If called, Write should always log the Entry and Fields; it should not
-// replicate the logic of Check.
+// replicate the logic of Check. Route cores are only written to if they
+// have the level of the Entry enabled.
 //
 //nolint:wrapcheck // simple wrapper for a *zap.Logger core.
 func (c *levelWrapCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
-	return c.c.Write(ent, fields)
+	c.ref.get().stats.write(ent)
+
+	if len(c.routes) == 0 {
+		return c.c.Write(ent, fields)
+	}
+
+	errs := []error{c.c.Write(ent, fields)}
+
+	for _, route := range c.routes {
+		if route.Enabled(ent.Level) {
+			errs = append(errs, route.Write(ent, fields))
+		}
+	}
+
+	return errors.Join(errs...)
 }
 
 // Sync flushes buffered logs (if any).
 //
 //nolint:wrapcheck // simple wrapper for a *zap.Logger core.
 func (c *levelWrapCore) Sync() error {
-	return c.c.Sync()
+	if len(c.routes) == 0 {
+		return c.c.Sync()
+	}
+
+	errs := []error{c.c.Sync()}
+
+	for _, route := range c.routes {
+		errs = append(errs, route.Sync())
+	}
+
+	return errors.Join(errs...)
 }
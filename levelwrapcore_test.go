@@ -0,0 +1,69 @@
+package zaptool_test
+
+import (
+	"testing"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLevelWrapCore_DerivedLoggersFollowLevel(t *testing.T) {
+	fac, observedLogs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	named := loglvls.Named("Server.Process")
+
+	derived := map[string]*zap.Logger{
+		"With":        named.With(zap.String("request_id", "abc")),
+		"Named":       named.Named("Child"),
+		"WithOptions": named.WithOptions(zap.AddCaller()),
+		"Chained":     named.With(zap.String("request_id", "abc")).Named("Child").With(zap.Int("attempt", 1)),
+	}
+
+	for name, derivedLogger := range derived {
+		t.Run(name, func(t *testing.T) {
+			observedLogs.TakeAll()
+
+			loglvls.SetLevel("Server.Process", zapcore.InfoLevel)
+			derivedLogger.Debug("should not log")
+
+			loglvls.SetLevel("Server.Process", zapcore.DebugLevel)
+			derivedLogger.Debug("should log")
+
+			loglvls.SetLevel("Server.Process", zapcore.ErrorLevel)
+			derivedLogger.Warn("should not log")
+			derivedLogger.Error("should log")
+
+			logs := observedLogs.TakeAll()
+			if len(logs) != 2 {
+				t.Fatalf("should contain 2 log messages, instead contained %d messages", len(logs))
+			}
+
+			for _, le := range logs {
+				if le.Message != "should log" {
+					t.Errorf("this message should not have been logged: %s", le.Message)
+				}
+			}
+		})
+	}
+
+	observedLogs.TakeAll()
+
+	derived["Chained"].Error("with fields")
+
+	logs := observedLogs.TakeAll()
+	if len(logs) != 1 {
+		t.Fatalf("should contain 1 log message, instead contained %d messages", len(logs))
+	}
+
+	if fields := logs[0].ContextMap(); fields["request_id"] != "abc" || fields["attempt"] != int64(1) {
+		t.Errorf("fields from With should be kept: %v", fields)
+	}
+
+	if logs[0].LoggerName != "Server.Process.Child" {
+		t.Errorf("logger name: got '%s', want 'Server.Process.Child'", logs[0].LoggerName)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -26,8 +28,42 @@ type LogManager interface {
 type LogLevels struct {
 	coreLogger *zap.Logger
 	iLogger    *zap.Logger
-	levels     map[string]*zap.AtomicLevel
+	levels     map[string]*levelEntry
+	children   map[string]int
+	rules      []LevelRule
+	sampling   []SamplingRule
+	routes     []Route
+	elevations []*elevation
+	subs       map[uint64]chan LevelEvent
+	nextSub    uint64
+	store      LevelStore
+	matchers   *matcherCache
+	global     *globalLevels
+	namePolicy NamePolicy
+	reclaimed  atomic.Uint64
+	saveSeq    uint64
 	lock       sync.RWMutex
+
+	// savedSeq is the sequence number of the last snapshot saved to the store.
+	savedSeq uint64
+	saveLock sync.Mutex
+
+	history     []LevelChange
+	historySize int
+	auditLogger *zap.Logger
+	historyLock sync.Mutex
+}
+
+// LevelRule is a level assignment made by SetLevel, it is kept so that
+// loggers created after the call still pick up the level.
+type LevelRule struct {
+	Pattern string        `json:"pattern"`
+	Level   zapcore.Level `json:"level"`
+}
+
+// String returns the rule in the same "pattern:level" form used by LogLevels.String.
+func (r LevelRule) String() string {
+	return fmt.Sprintf("%s:%s", r.Pattern, r.Level.String())
 }
 
 // NewLogLevels returns a new LogLevels ready for use.
@@ -35,11 +71,28 @@ func NewLogLevels(coreLogger *zap.Logger, opts ...interface{}) *LogLevels {
 	out := &LogLevels{
 		coreLogger: coreLogger,
 		iLogger:    coreLogger,
-		levels:     map[string]*zap.AtomicLevel{},
+		levels:     map[string]*levelEntry{},
+		children:   map[string]int{},
+		subs:       map[uint64]chan LevelEvent{},
+		global:     newGlobalLevels(),
+		matchers:   &matcherCache{},
 		lock:       sync.RWMutex{},
+
+		historySize: defaultHistorySize,
+	}
+
+	for _, opti := range opts {
+		if policy, ok := opti.(NamePolicy); ok {
+			out.namePolicy = policy
+			out.matchers.foldCase = policy == NameCaseInsensitive
+		}
 	}
+
 	out.iLogger = out.Named("Internal.LogLevels", opts...)
 
+	// the internal logger is used while the lock is held, so it must never be reclaimed.
+	out.levels[out.normaliseName("Internal.LogLevels")].pinned = true
+
 	for _, opti := range opts {
 		if opt, ok := opti.(func(*LogLevels)); ok {
 			opt(out)
@@ -56,21 +109,52 @@ func LogLevelsInternalLevel(lvl interface{}) func(*LogLevels) {
 }
 
 // NewLevel returns a zap.AtomicLevel reference to the stored named level.
+//
+// Levels returned by NewLevel are never reclaimed (see ReclaimIdle) as their use can not
+// be tracked.
 func (a *LogLevels) NewLevel(name string) *zap.AtomicLevel {
+	return a.newEntry(a.normaliseName(name), zapcore.InfoLevel, true).level
+}
+
+// newEntry returns the stored named level entry, creating it if it does not exist, pinned
+// entries are never reclaimed.
+//
+// A newly created level takes the level of the most specific matching rule, otherwise
+// it inherits from its nearest ancestor, falling back to the default level.
+func (a *LogLevels) newEntry(name string, def zapcore.Level, pin bool) *levelEntry {
 	a.lock.Lock()
 	defer a.lock.Unlock()
 
 	if v, ok := a.levels[name]; ok {
+		v.pinned = v.pinned || pin
+		v.seen.Store(true)
+
 		return v
 	}
 
-	atom := zap.NewAtomicLevelAt(zapcore.InfoLevel)
-	a.levels[name] = &atom
+	entry := newLevelEntry(def)
+	entry.pinned = pin
+	entry.sampler.set(a.samplingPolicy(name))
 
-	return &atom
+	if level, ok := a.ruleLevel(name); ok {
+		entry.configured = level
+		entry.explicit = true
+	} else if level, ok := a.inheritedLevel(name); ok {
+		entry.configured = level
+	}
+
+	atom := zap.NewAtomicLevelAt(a.effectiveLevel(name, entry.configured, entry.explicit))
+	entry.level = &atom
+
+	a.addEntry(name, entry)
+	a.inheritFrom(name, name)
+
+	return entry
 }
 
-func (a *LogLevels) parseLevel(v interface{}) (zapcore.Level, bool) {
+// parseLevel attempts to typecast the value against string, zapcore.Level
+// and *zap.AtomicLevel.
+func parseLevel(v interface{}) (zapcore.Level, bool) {
 	switch lvl := v.(type) {
 	case zapcore.Level:
 		return lvl, true
@@ -89,62 +173,210 @@ func (a *LogLevels) parseLevel(v interface{}) (zapcore.Level, bool) {
 	return zapcore.InfoLevel, false
 }
 
-// doesKeyMatch tests if a key matches.
-func (a *LogLevels) doesKeyMatch(key, check string) bool {
-	if strings.EqualFold(key, check) {
-		return true
+// levelString returns the name of the level, or "invalid" for an invalid level.
+func levelString(lvl zapcore.Level) string {
+	if lvl == zapcore.InvalidLevel {
+		return "invalid"
 	}
 
-	// if is is a single '*' then it's a wildcard and true.
-	if len(check) == 1 && check == "*" {
-		return true
-	}
+	return lvl.String()
+}
 
-	switch {
-	case strings.HasPrefix(check, "*") && strings.HasSuffix(check, "*"):
-		// wildcard both ends (can't be a single * otherwise initial check would fail)
-		return strings.Contains(key, check[1:len(check)-1])
-	case strings.HasPrefix(check, "*"):
-		// wildcard at start
-		return strings.HasSuffix(key, check[1:])
-	case strings.HasSuffix(check, "*"):
-		// wildcard at end
-		return strings.HasPrefix(key, check[:len(check)-1])
+// doesKeyMatch tests if a key matches the pattern (see keyMatcher for the pattern syntax),
+// invalid patterns do not match any key.
+func (a *LogLevels) doesKeyMatch(key, check string) bool {
+	m, err := a.matchers.get(check)
+	if err != nil {
+		return false
 	}
 
-	return false
+	return m.match(key)
 }
 
 // SetLevel attempts to set the level supplied, it will attempt to typecast the value
 // against string, zapcore.Level and *zap.AtomicLevel.
+//
+// The name (or wildcard pattern) is also kept as a rule so loggers created later
+// that match it start at the supplied level. It returns true if any existing
+// levels matched, including levels that inherit from a matching ancestor.
 func (a *LogLevels) SetLevel(name string, lvl interface{}) bool {
+	return a.SetLevelBy(name, lvl, ChangeSource{})
+}
+
+// SetLevelBy is SetLevel with the source of the change recorded in the history (and
+// audit logger if there is one).
+func (a *LogLevels) SetLevelBy(name string, lvl interface{}, src ChangeSource) bool {
+	name = a.normaliseName(name)
 	a.iLogger.Debug("SetLevel", zap.String("name", name))
 
-	found := false
+	level, ok := parseLevel(lvl)
+	if !ok {
+		return false
+	}
+
+	if err := ValidatePattern(name); err != nil {
+		a.iLogger.Debug("invalid pattern", zap.String("name", name), zap.Error(err))
+		return false
+	}
 
 	a.lock.Lock()
-	defer a.lock.Unlock()
+	found := a.setLevel(name, level)
+	pending := a.persist()
+	a.lock.Unlock()
+
+	pending.save()
+
+	a.record(LevelChange{
+		Action:  LevelChangeSet,
+		Pattern: name,
+		Level:   level.String(),
+		Matched: found,
+		Source:  src,
+	})
+
+	return found
+}
+
+// setLevel stores the rule and sets the level of all matching names.
+//
+// Callers must hold the lock.
+func (a *LogLevels) setLevel(name string, level zapcore.Level) bool {
+	found := a.anyMatch(name)
+
+	a.addRule(name, level)
 
 	for itemKey, val := range a.levels {
 		if a.doesKeyMatch(itemKey, name) {
-			if level, ok := a.parseLevel(lvl); ok {
-				a.iLogger.Debug(
-					"setting level for name",
-					zap.String("name", name),
-					zap.String("match", itemKey),
-					zap.String("level", level.String()),
-				)
-				val.SetLevel(level)
-
-				found = true
-			}
+			a.iLogger.Debug(
+				"setting level for name",
+				zap.String("name", name),
+				zap.String("match", itemKey),
+				zap.String("level", level.String()),
+			)
+			val.explicit = true
+			a.setEntryLevel(itemKey, val, level, name)
 		}
 	}
-	// }
+
+	a.inheritMatching(name, name)
+
+	return found
+}
+
+// ClearLevel removes the explicit level (and rule) for the name or wildcard pattern,
+// matching levels go back to inheriting from their nearest ancestor, or their
+// default level if there is none. It returns true if any existing levels matched.
+func (a *LogLevels) ClearLevel(name string) bool {
+	return a.ClearLevelBy(name, ChangeSource{})
+}
+
+// ClearLevelBy is ClearLevel with the source of the change recorded in the history (and
+// audit logger if there is one).
+func (a *LogLevels) ClearLevelBy(name string, src ChangeSource) bool {
+	name = a.normaliseName(name)
+	a.iLogger.Debug("ClearLevel", zap.String("name", name))
+
+	a.lock.Lock()
+	found := a.clearLevel(name)
+	pending := a.persist()
+	a.lock.Unlock()
+
+	pending.save()
+
+	a.record(LevelChange{
+		Action:  LevelChangeClear,
+		Pattern: name,
+		Matched: found,
+		Source:  src,
+	})
+
+	return found
+}
+
+// clearLevel removes the rule and resets the matching levels.
+//
+// Callers must hold the lock.
+func (a *LogLevels) clearLevel(name string) bool {
+	found := false
+
+	a.removeRule(name)
+
+	for itemKey, val := range a.levels {
+		if a.doesKeyMatch(itemKey, name) {
+			a.resetEntry(itemKey, val, name)
+
+			found = true
+		}
+	}
+
+	a.inheritMatching(name, name)
 
 	return found
 }
 
+// addRule stores a rule, replacing any existing rule with the same pattern and
+// moving it to the end so it takes precedence over earlier rules.
+func (a *LogLevels) addRule(pattern string, level zapcore.Level) {
+	a.removeRule(pattern)
+	a.rules = append(a.rules, LevelRule{Pattern: pattern, Level: level})
+}
+
+// removeRule removes the rule with the pattern if it exists.
+func (a *LogLevels) removeRule(pattern string) {
+	for idx, rule := range a.rules {
+		if rule.Pattern == pattern {
+			a.rules = append(a.rules[:idx], a.rules[idx+1:]...)
+			return
+		}
+	}
+}
+
+// ruleLevel returns the level of the most specific rule that matches the name, if more
+// than one rule is equally specific the last one set wins.
+//
+// Names without wildcards are the most specific, followed by wildcard patterns ordered by
+// the number of non-wildcard characters, regular expressions are the least specific.
+func (a *LogLevels) ruleLevel(name string) (zapcore.Level, bool) {
+	found := false
+	best := regexpSpecificity
+	level := zapcore.InfoLevel
+
+	for _, rule := range a.rules {
+		m, err := a.matchers.get(rule.Pattern)
+		if err != nil || !m.match(name) || m.specificity < best {
+			continue
+		}
+
+		found = true
+		best = m.specificity
+		level = rule.Level
+	}
+
+	return level, found
+}
+
+// Rules returns a copy of the stored level rules in the order they are applied.
+func (a *LogLevels) Rules() []LevelRule {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	out := make([]LevelRule, len(a.rules))
+	copy(out, a.rules)
+
+	return out
+}
+
+// DeleteRule removes the rule for the pattern, levels already set by the rule are not changed.
+func (a *LogLevels) DeleteRule(pattern string) {
+	pattern = a.normaliseName(pattern)
+	a.lock.Lock()
+	a.removeRule(pattern)
+	pending := a.persist()
+	a.lock.Unlock()
+
+	pending.save()
+}
+
 // String returns a string representation of the currently stored loggers and their levels.
 func (a *LogLevels) String() string {
 	a.lock.RLock()
@@ -153,12 +385,7 @@ func (a *LogLevels) String() string {
 	out := []string{}
 
 	for k, v := range a.levels {
-		if v.Level() == zapcore.InvalidLevel {
-			out = append(out, k+":invalid")
-			continue
-		}
-
-		out = append(out, fmt.Sprintf("%s:%s", k, v.String()))
+		out = append(out, k+":"+levelString(v.level.Level()))
 	}
 
 	sort.Strings(out)
@@ -172,7 +399,31 @@ func (a *LogLevels) Iterator(f func(string, *zap.AtomicLevel) error) error {
 	defer a.lock.RUnlock()
 
 	for k, v := range a.levels {
-		if err := f(k, v); err != nil {
+		if err := f(k, v.level); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MatchIterator runs a callback function over the levels that match the name (or wildcard
+// pattern) item by item.
+func (a *LogLevels) MatchIterator(pattern string, f func(string, *zap.AtomicLevel) error) error {
+	m, err := a.matchers.get(a.normaliseName(pattern))
+	if err != nil {
+		return err
+	}
+
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	for k, v := range a.levels {
+		if !m.match(k) {
+			continue
+		}
+
+		if err := f(k, v.level); err != nil {
 			return err
 		}
 	}
@@ -180,44 +431,103 @@ func (a *LogLevels) Iterator(f func(string, *zap.AtomicLevel) error) error {
 	return nil
 }
 
-// IsLogger returns true if there is a logger that matches.
+// IsLogger returns true if there is a logger that matches, names are matched regardless of
+// case as they are by SetLevel.
 func (a *LogLevels) IsLogger(name string) bool {
 	a.lock.RLock()
 	defer a.lock.RUnlock()
 
-	_, ok := a.levels[name]
+	_, _, ok := a.lookupLevel(a.normaliseName(name))
 	return ok
 }
 
-// DeleteLevel removes the entry from the list.
+// DeleteLevel removes the entries matching the name (or wildcard pattern) from the list.
 func (a *LogLevels) DeleteLevel(name string) {
+	a.DeleteLevelBy(name, ChangeSource{})
+}
+
+// DeleteLevelBy is DeleteLevel with the source of the change recorded in the history (and
+// audit logger if there is one).
+func (a *LogLevels) DeleteLevelBy(name string, src ChangeSource) {
+	name = a.normaliseName(name)
+
 	a.lock.Lock()
-	defer a.lock.Unlock()
+	found := a.deleteLevel(name)
+	pending := a.persist()
+	a.lock.Unlock()
+
+	pending.save()
+
+	a.record(LevelChange{
+		Action:  LevelChangeDelete,
+		Pattern: name,
+		Matched: found,
+		Source:  src,
+	})
+}
+
+// deleteLevel removes the matching entries, it returns true if any entries were removed.
+//
+// Callers must hold the lock.
+func (a *LogLevels) deleteLevel(name string) bool {
+	found := false
+
+	for itemKey, v := range a.levels {
+		if !a.doesKeyMatch(itemKey, name) {
+			continue
+		}
 
-	delete(a.levels, name)
+		a.removeEntry(itemKey)
+		found = true
+
+		a.publish(LevelEvent{
+			Name:    itemKey,
+			Old:     v.level.Level(),
+			New:     zapcore.InvalidLevel,
+			Pattern: name,
+			Deleted: true,
+			Time:    time.Now(),
+		})
+	}
+
+	a.inheritMatching(name, name)
+
+	return found
 }
 
 // Named returns a named *zap.Logger if any additional parameters are specified it will
 // try to determine if they represent a log level (by string, zapcore.Level or *zap.AtomicLevel).
+//
+// The default level for a newly created name is debug if the core logger has debug
+// enabled, otherwise info, after which any matching rules are applied. Entries are
+// also written to the cores of any matching routes (see AddRoute).
 func (a *LogLevels) Named(name string, opts ...interface{}) *zap.Logger {
-	lvl := a.NewLevel(name)
+	def := zapcore.InfoLevel
 	if a.coreLogger.Core().Enabled(zapcore.DebugLevel) {
-		lvl.SetLevel(zapcore.DebugLevel)
+		def = zapcore.DebugLevel
 	}
 
+	key := a.normaliseName(name)
+	ref := &entryRef{owner: a, name: key}
+	ref.entry.Store(a.newEntry(key, def, false))
+
 	for _, opt := range opts {
-		switch v := opt.(type) {
-		case zapcore.Level:
-			a.SetLevel(name, v.String())
-		case zap.AtomicLevel, *zap.AtomicLevel:
-			a.SetLevel(name, v)
+		switch opt.(type) {
+		case zapcore.Level, zap.AtomicLevel, *zap.AtomicLevel:
+			if level, ok := parseLevel(opt); ok {
+				a.setExplicitLevel(key, level)
+			}
 		}
 	}
 
 	return a.coreLogger.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		c, routes := a.routeCores(key, c)
+
 		return &levelWrapCore{
-			lvl: *lvl,
-			c:   c,
+			ref:    ref,
+			global: a.global,
+			c:      c,
+			routes: routes,
 		}
 	})).Named(name)
 }
@@ -164,3 +164,151 @@ func TestLogLevels_NotALevel(t *testing.T) {
 		t.Error("should not contain a 'should not log' message")
 	}
 }
+
+func TestLogLevels_RulesApplyToLaterLoggers(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+
+	if loglvls.SetLevel("Server.*", zapcore.DebugLevel) {
+		t.Error("SetLevel should return false when no existing levels match")
+	}
+
+	if loglvls.SetLevel("Server.Process", "notalevel") {
+		t.Error("SetLevel should return false for an invalid level")
+	}
+
+	loglvls.SetLevel("*.Worker", zapcore.WarnLevel)
+
+	loglvls.Named("Server.Process")
+	loglvls.Named("Server.Process.Worker")
+	loglvls.Named("Client")
+
+	expect := "Client:info,Internal.LogLevels:info,Server.Process.Worker:warn,Server.Process:debug"
+	if loglvls.String() != expect {
+		t.Errorf("levels: got '%s', want '%s'", loglvls.String(), expect)
+	}
+
+	rules := loglvls.Rules()
+	if len(rules) != 2 {
+		t.Fatalf("rules: got %d rules, want 2", len(rules))
+	}
+
+	if rules[0].String() != "Server.*:debug" || rules[1].String() != "*.Worker:warn" {
+		t.Errorf("rules: got '%s' and '%s'", rules[0].String(), rules[1].String())
+	}
+
+	loglvls.SetLevel("Server.*", zapcore.ErrorLevel)
+
+	rules = loglvls.Rules()
+	if len(rules) != 2 || rules[1].String() != "Server.*:error" {
+		t.Errorf("rules: replaced rule should be moved to the end: %v", rules)
+	}
+
+	loglvls.Named("Server.Other.Worker")
+
+	if !strings.Contains(loglvls.String(), "Server.Other.Worker:error") {
+		t.Errorf("later rule should take precedence: %s", loglvls.String())
+	}
+
+	loglvls.DeleteRule("Server.*")
+
+	if len(loglvls.Rules()) != 1 {
+		t.Errorf("rules: got %d rules after DeleteRule, want 1", len(loglvls.Rules()))
+	}
+}
+
+func TestLogLevels_HierarchicalInheritance(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+
+	loglvls.Named("Server")
+	worker := loglvls.Named("Server.Process.Worker")
+	loglvls.Named("Server.Process")
+
+	if !loglvls.SetLevel("Server", zapcore.DebugLevel) {
+		t.Error("SetLevel should return true when the level exists")
+	}
+
+	expect := "Internal.LogLevels:info,Server.Process.Worker:debug,Server.Process:debug,Server:debug"
+	if loglvls.String() != expect {
+		t.Errorf("levels: got '%s', want '%s'", loglvls.String(), expect)
+	}
+
+	if !worker.Core().Enabled(zapcore.DebugLevel) {
+		t.Error("Server.Process.Worker should inherit debug from Server")
+	}
+
+	loglvls.SetLevel("Server.Process", zapcore.WarnLevel)
+	loglvls.SetLevel("Server", zapcore.ErrorLevel)
+
+	expect = "Internal.LogLevels:info,Server.Process.Worker:warn,Server.Process:warn,Server:error"
+	if loglvls.String() != expect {
+		t.Errorf("levels: got '%s', want '%s'", loglvls.String(), expect)
+	}
+
+	if !loglvls.ClearLevel("Server.Process") {
+		t.Error("ClearLevel should return true when the level exists")
+	}
+
+	expect = "Internal.LogLevels:info,Server.Process.Worker:error,Server.Process:error,Server:error"
+	if loglvls.String() != expect {
+		t.Errorf("levels: got '%s', want '%s'", loglvls.String(), expect)
+	}
+
+	loglvls.ClearLevel("Server")
+
+	expect = "Internal.LogLevels:info,Server.Process.Worker:info,Server.Process:info,Server:info"
+	if loglvls.String() != expect {
+		t.Errorf("levels: got '%s', want '%s'", loglvls.String(), expect)
+	}
+}
+
+func TestLogLevels_InheritAfterDelete(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+
+	loglvls.Named("Server.Process.Worker")
+	loglvls.Named("Server.Other")
+	loglvls.DeleteLevel("Server.Process.Worker")
+	loglvls.Named("Server", zapcore.WarnLevel)
+
+	expect := "Internal.LogLevels:info,Server.Other:warn,Server:warn"
+	if loglvls.String() != expect {
+		t.Errorf("levels: got '%s', want '%s'", loglvls.String(), expect)
+	}
+
+	loglvls.DeleteLevel("Server.Other")
+	loglvls.Named("Server.Process.Worker")
+	loglvls.Named("Server.Process", zapcore.ErrorLevel)
+
+	expect = "Internal.LogLevels:info,Server.Process.Worker:error,Server.Process:error,Server:warn"
+	if loglvls.String() != expect {
+		t.Errorf("levels: got '%s', want '%s'", loglvls.String(), expect)
+	}
+}
+
+func TestLogLevels_InheritFromRuleWithoutParentLogger(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+
+	loglvls.Named("Server.Process")
+
+	if !loglvls.SetLevel("Server", zapcore.DebugLevel) {
+		t.Error("SetLevel should return true when descendant levels changed")
+	}
+
+	loglvls.Named("Server.Other")
+
+	expect := "Internal.LogLevels:info,Server.Other:debug,Server.Process:debug"
+	if loglvls.String() != expect {
+		t.Errorf("levels: got '%s', want '%s'", loglvls.String(), expect)
+	}
+}
@@ -0,0 +1,253 @@
+package zaptool
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidPattern is returned when a name pattern can not be compiled.
+var ErrInvalidPattern = errors.New("invalid pattern")
+
+// matcherCacheSize is the number of compiled patterns kept before the cache is reset.
+const matcherCacheSize = 1024
+
+// exactSpecificity is the specificity of a pattern without wildcards, it is higher than
+// any wildcard pattern can be.
+const exactSpecificity = 1 << 20
+
+// regexpSpecificity is the specificity of a regular expression pattern, it is lower than
+// any other pattern.
+const regexpSpecificity = -1
+
+type matcherKind int
+
+const (
+	matcherExact matcherKind = iota
+	matcherAny
+	matcherPrefix
+	matcherSuffix
+	matcherContains
+	matcherGlob
+	matcherRegexp
+	matcherScoped
+)
+
+// keyMatcher is a compiled name pattern.
+//
+// Patterns are one of:
+//   - a name without wildcards, matched regardless of case (as SetLevel always has).
+//   - "*", matching every name.
+//   - a single leading and/or trailing "*" (eg. "Server.*", "*.Worker", "*Process*"),
+//     matching names with the prefix, suffix or substring.
+//   - a segment glob, where "*" matches within a single dot-separated segment and "**"
+//     matches zero or more segments (eg. "Server.*.Worker", "**.DB").
+//   - a regular expression between slashes matched against the whole name
+//     (eg. "/(api|grpc)\..*/").
+//   - a prefix followed by a pattern in braces (eg. "Server.{*Worker}"), matching names
+//     under the prefix where the rest of the name matches the pattern, as used by
+//     SubLogLevels. It is as specific as the pattern with the prefix added.
+type keyMatcher struct {
+	kind        matcherKind
+	literal     string
+	segments    []string
+	re          *regexp.Regexp
+	inner       *keyMatcher
+	specificity int
+}
+
+// isRegexpPattern returns true if the pattern is a regular expression between slashes.
+func isRegexpPattern(pattern string) bool {
+	return len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/")
+}
+
+// splitScopedPattern returns the prefix and the pattern in braces of a scoped pattern
+// (eg. "Server.{*Worker}").
+func splitScopedPattern(pattern string) (string, string, bool) {
+	idx := strings.Index(pattern, ".{")
+	if idx <= 0 || !strings.HasSuffix(pattern, "}") || idx+3 >= len(pattern) {
+		return "", "", false
+	}
+
+	return pattern[:idx], pattern[idx+2 : len(pattern)-1], true
+}
+
+// compilePattern compiles the pattern into a keyMatcher, if foldCase is set regular
+// expressions are matched case-insensitively.
+func compilePattern(pattern string, foldCase bool) (*keyMatcher, error) {
+	if prefix, scoped, ok := splitScopedPattern(pattern); ok {
+		inner, err := compilePattern(scoped, foldCase)
+		if err != nil {
+			return nil, err
+		}
+
+		out := &keyMatcher{
+			kind:        matcherScoped,
+			literal:     prefix + ".",
+			inner:       inner,
+			specificity: inner.specificity,
+		}
+
+		if inner.kind != matcherRegexp {
+			out.specificity += len(out.literal)
+		}
+
+		return out, nil
+	}
+
+	if isRegexpPattern(pattern) {
+		flags := ""
+		if foldCase {
+			flags = "(?i)"
+		}
+
+		re, err := regexp.Compile(flags + "^(?:" + pattern[1:len(pattern)-1] + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %w", ErrInvalidPattern, pattern, err)
+		}
+
+		return &keyMatcher{kind: matcherRegexp, re: re, specificity: regexpSpecificity}, nil
+	}
+
+	out := &keyMatcher{
+		literal:     pattern,
+		specificity: len(pattern) - strings.Count(pattern, "*"),
+	}
+
+	inner := strings.TrimSuffix(strings.TrimPrefix(pattern, "*"), "*")
+
+	switch {
+	case pattern == "":
+		return nil, fmt.Errorf("%w: empty pattern", ErrInvalidPattern)
+	case !strings.Contains(pattern, "*"):
+		out.kind = matcherExact
+		out.specificity = exactSpecificity + len(pattern)
+	case strings.Trim(pattern, "*") == "" && !strings.Contains(pattern, "**"):
+		out.kind = matcherAny
+	case strings.Contains(inner, "*"):
+		out.kind = matcherGlob
+		out.segments = strings.Split(pattern, ".")
+	case strings.HasPrefix(pattern, "*") && strings.HasSuffix(pattern, "*"):
+		out.kind = matcherContains
+		out.literal = inner
+	case strings.HasPrefix(pattern, "*"):
+		out.kind = matcherSuffix
+		out.literal = inner
+	default:
+		out.kind = matcherPrefix
+		out.literal = inner
+	}
+
+	return out, nil
+}
+
+// match returns true if the key matches the pattern.
+func (m *keyMatcher) match(key string) bool {
+	switch m.kind {
+	case matcherExact:
+		return strings.EqualFold(key, m.literal)
+	case matcherAny:
+		return true
+	case matcherPrefix:
+		return strings.HasPrefix(key, m.literal)
+	case matcherSuffix:
+		return strings.HasSuffix(key, m.literal)
+	case matcherContains:
+		return strings.Contains(key, m.literal)
+	case matcherGlob:
+		return matchSegments(m.segments, strings.Split(key, "."))
+	case matcherRegexp:
+		return m.re.MatchString(key)
+	case matcherScoped:
+		return strings.HasPrefix(key, m.literal) && m.inner.match(key[len(m.literal):])
+	}
+
+	return false
+}
+
+// matchSegments matches the dot-separated segments of a name against the segments of
+// a glob pattern, a "**" segment matches zero or more segments.
+func matchSegments(pattern, key []string) bool {
+	if len(pattern) == 0 {
+		return len(key) == 0
+	}
+
+	if pattern[0] == "**" {
+		for idx := 0; idx <= len(key); idx++ {
+			if matchSegments(pattern[1:], key[idx:]) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return len(key) > 0 && matchWildcard(pattern[0], key[0]) && matchSegments(pattern[1:], key[1:])
+}
+
+// matchWildcard matches a single segment where "*" matches any run of characters.
+func matchWildcard(pattern, s string) bool {
+	star, match := -1, 0
+	pIdx, sIdx := 0, 0
+
+	for sIdx < len(s) {
+		switch {
+		case pIdx < len(pattern) && pattern[pIdx] == '*':
+			star, match = pIdx, sIdx
+			pIdx++
+		case pIdx < len(pattern) && pattern[pIdx] == s[sIdx]:
+			pIdx++
+			sIdx++
+		case star >= 0:
+			match++
+			pIdx, sIdx = star+1, match
+		default:
+			return false
+		}
+	}
+
+	for pIdx < len(pattern) && pattern[pIdx] == '*' {
+		pIdx++
+	}
+
+	return pIdx == len(pattern)
+}
+
+// matcherCache keeps compiled patterns so they are not compiled for every comparison.
+type matcherCache struct {
+	lock     sync.Mutex
+	foldCase bool
+	matchers map[string]*keyMatcher
+}
+
+// get returns the compiled pattern, compiling and caching it if required.
+func (c *matcherCache) get(pattern string) (*keyMatcher, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if m, ok := c.matchers[pattern]; ok {
+		return m, nil
+	}
+
+	m, err := compilePattern(pattern, c.foldCase)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.matchers == nil || len(c.matchers) >= matcherCacheSize {
+		c.matchers = map[string]*keyMatcher{}
+	}
+
+	c.matchers[pattern] = m
+
+	return m, nil
+}
+
+// ValidatePattern returns an error wrapping ErrInvalidPattern if the name pattern can not
+// be compiled.
+func ValidatePattern(pattern string) error {
+	_, err := compilePattern(pattern, false)
+	return err
+}
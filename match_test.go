@@ -0,0 +1,110 @@
+package zaptool_test
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func matchingNames(t *testing.T, loglvls *zaptool.LogLevels, pattern string) string {
+	t.Helper()
+
+	names := []string{}
+
+	if err := loglvls.MatchIterator(pattern, func(name string, _ *zap.AtomicLevel) error {
+		names = append(names, name)
+		return nil
+	}); err != nil {
+		t.Fatalf("MatchIterator(%q) returned error: %s", pattern, err)
+	}
+
+	sort.Strings(names)
+
+	return strings.Join(names, ",")
+}
+
+func TestLogLevels_MatchIterator(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	for _, name := range []string{
+		"Server", "Server.Process", "Server.Process.Worker", "Server.Queue.Worker",
+		"Client.DB", "Server.Process.DB", "api.Handler", "grpc.Handler", "web.Handler",
+	} {
+		loglvls.Named(name)
+	}
+
+	tests := []struct {
+		pattern string
+		expect  string
+	}{
+		{"Server.Process", "Server.Process"},
+		{"Server.*", "Server.Process,Server.Process.DB,Server.Process.Worker,Server.Queue.Worker"},
+		{"*.Worker", "Server.Process.Worker,Server.Queue.Worker"},
+		{"*Handler*", "api.Handler,grpc.Handler,web.Handler"},
+		{"Server.*.Worker", "Server.Process.Worker,Server.Queue.Worker"},
+		{"Server.**", "Server,Server.Process,Server.Process.DB,Server.Process.Worker,Server.Queue.Worker"},
+		{"**.DB", "Client.DB,Server.Process.DB"},
+		{"Server.Proc*.*", "Server.Process.DB,Server.Process.Worker"},
+		{"/(api|grpc)\\..*/", "api.Handler,grpc.Handler"},
+		{"/Handler/", ""},
+		{"Server.{*Worker}", "Server.Process.Worker,Server.Queue.Worker"},
+		{"Server.{Process}", "Server.Process"},
+		{"Server.{*ss*}", "Server.Process,Server.Process.DB,Server.Process.Worker"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if got := matchingNames(t, loglvls, tt.pattern); got != tt.expect {
+				t.Errorf("names: got '%s', want '%s'", got, tt.expect)
+			}
+		})
+	}
+
+	if err := loglvls.MatchIterator("/(/", nil); !errors.Is(err, zaptool.ErrInvalidPattern) {
+		t.Errorf("MatchIterator should return ErrInvalidPattern: %v", err)
+	}
+
+	if loglvls.SetLevel("/(/", zapcore.DebugLevel) {
+		t.Error("SetLevel should return false for an invalid pattern")
+	}
+
+	if len(loglvls.Rules()) != 0 {
+		t.Errorf("invalid patterns should not be stored as rules: %v", loglvls.Rules())
+	}
+
+	loglvls.DeleteLevel("**.Handler")
+
+	if got := matchingNames(t, loglvls, "*"); strings.Contains(got, "Handler") {
+		t.Errorf("DeleteLevel should delete all matching levels: %s", got)
+	}
+}
+
+func TestLogLevels_MostSpecificRuleWins(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+
+	loglvls.SetLevel("Server.Process.Worker", zapcore.ErrorLevel)
+	loglvls.SetLevel("Server.*.Worker", zapcore.WarnLevel)
+	loglvls.SetLevel("**", zapcore.DebugLevel)
+	loglvls.SetLevel("/Server\\..*/", zapcore.DPanicLevel)
+
+	loglvls.Named("Server.Process.Worker")
+	loglvls.Named("Server.Queue.Worker")
+	loglvls.Named("Server.Queue")
+	loglvls.Named("Client")
+
+	expect := "Client:debug,Internal.LogLevels:debug,Server.Process.Worker:error,Server.Queue.Worker:warn,Server.Queue:debug"
+	if loglvls.String() != expect {
+		t.Errorf("levels: got '%s', want '%s'", loglvls.String(), expect)
+	}
+}
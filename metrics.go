@@ -0,0 +1,200 @@
+package zaptool
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// metricsContentType is the content type of the Prometheus text exposition format.
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// metricsLabelReplacer escapes label values for the Prometheus text exposition format.
+//
+//nolint:gochecknoglobals // same as html.htmlEscaper.
+var metricsLabelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// statsIterator is implemented by a LogManager that counts entries (eg. LogLevels).
+type statsIterator interface {
+	StatsIterator(f func(LoggerStats) error) error
+}
+
+// reclaimCounter is implemented by a LogManager that reclaims idle levels (eg. LogLevels).
+type reclaimCounter interface {
+	Reclaimed() uint64
+}
+
+// metricsHandler is the http.Handler implementation for MetricsHTTPHandler.
+type metricsHandler struct {
+	logmgr LogManager
+}
+
+// ServeHTTP writes the metrics on GET.
+func (h metricsHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, fmt.Sprintf("method %s not allowed", req.Method), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", metricsContentType)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	if req.Method == http.MethodHead {
+		return
+	}
+
+	_ = WriteMetrics(w, h.logmgr)
+}
+
+// MetricsHTTPHandler returns a http.Handler that serves the metrics of the LogManager in the
+// Prometheus text exposition format (see WriteMetrics).
+func MetricsHTTPHandler(logmgr LogManager) http.Handler {
+	return metricsHandler{
+		logmgr: logmgr,
+	}
+}
+
+// WriteMetrics writes the metrics of the LogManager in the Prometheus text exposition format.
+//
+//   - zaptool_log_level (gauge): the current level of each logger, labelled with the logger
+//     name, the value is the zap level (-1 for debug through to 5 for fatal). The level is
+//     not a label so a logger keeps the same series when its level changes.
+//
+// If the LogManager counts entries (eg. LogLevels) the following are also written.
+//
+//   - zaptool_log_entries_total (counter): entries written by logger and level.
+//   - zaptool_log_entries_dropped_total (counter): entries dropped for being below the
+//     level by logger and level.
+//   - zaptool_log_entries_sampled_total (counter): entries dropped by the sampling policy
+//     by logger and level.
+//   - zaptool_log_last_write_timestamp_seconds (gauge): the time of the last entry written
+//     by each logger that has written an entry.
+//
+// If the LogManager reclaims idle levels (eg. LogLevels) the following is also written.
+//
+//   - zaptool_log_levels_reclaimed_total (counter): idle levels removed by ReclaimIdle.
+func WriteMetrics(w io.Writer, logmgr LogManager) error {
+	buf := bufio.NewWriter(w)
+
+	levels := map[string]zapcore.Level{}
+	names := []string{}
+
+	if err := logmgr.Iterator(func(name string, lvl *zap.AtomicLevel) error {
+		levels[name] = lvl.Level()
+		names = append(names, name)
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("unable to list levels: %w", err)
+	}
+
+	sort.Strings(names)
+
+	writeMetricHeader(buf, "zaptool_log_level", "gauge",
+		"Current level of the logger (-1 debug, 0 info, 1 warn, 2 error, 3 dpanic, 4 panic, 5 fatal).")
+
+	for _, name := range names {
+		fmt.Fprintf(buf, "zaptool_log_level{logger=\"%s\"} %d\n", escapeMetricLabel(name), int(levels[name]))
+	}
+
+	if si, ok := logmgr.(statsIterator); ok {
+		if err := writeStatsMetrics(buf, si); err != nil {
+			return err
+		}
+	}
+
+	if r, ok := logmgr.(reclaimCounter); ok {
+		writeMetricHeader(buf, "zaptool_log_levels_reclaimed_total", "counter",
+			"Number of idle levels removed.")
+		fmt.Fprintf(buf, "zaptool_log_levels_reclaimed_total %d\n", r.Reclaimed())
+	}
+
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("unable to write metrics: %w", err)
+	}
+
+	return nil
+}
+
+// writeStatsMetrics writes the entry counters sorted by logger name.
+func writeStatsMetrics(buf *bufio.Writer, si statsIterator) error {
+	stats := []LoggerStats{}
+	if err := si.StatsIterator(func(s LoggerStats) error {
+		stats = append(stats, s)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("unable to list logger stats: %w", err)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+
+	writeMetricHeader(buf, "zaptool_log_entries_total", "counter", "Number of log entries written.")
+	writeLevelCounts(buf, "zaptool_log_entries_total", stats, func(s LoggerStats) map[zapcore.Level]uint64 {
+		return s.Written
+	})
+
+	writeMetricHeader(buf, "zaptool_log_entries_dropped_total", "counter",
+		"Number of log entries dropped for being below the level.")
+	writeLevelCounts(buf, "zaptool_log_entries_dropped_total", stats, func(s LoggerStats) map[zapcore.Level]uint64 {
+		return s.Dropped
+	})
+
+	writeMetricHeader(buf, "zaptool_log_entries_sampled_total", "counter",
+		"Number of log entries dropped by the sampling policy.")
+	writeLevelCounts(buf, "zaptool_log_entries_sampled_total", stats, func(s LoggerStats) map[zapcore.Level]uint64 {
+		return s.Sampled
+	})
+
+	writeMetricHeader(buf, "zaptool_log_last_write_timestamp_seconds", "gauge",
+		"Time of the last log entry written.")
+
+	for _, s := range stats {
+		if s.LastWrite.IsZero() {
+			continue
+		}
+
+		fmt.Fprintf(buf, "zaptool_log_last_write_timestamp_seconds{logger=\"%s\"} %.3f\n",
+			escapeMetricLabel(s.Name), float64(s.LastWrite.UnixNano())/1e9)
+	}
+
+	return nil
+}
+
+// writeLevelCounts writes a sample for every level of every logger, levels without entries
+// are written as zero so the series exist before the first entry.
+func writeLevelCounts(
+	buf *bufio.Writer,
+	metric string,
+	stats []LoggerStats,
+	counts func(LoggerStats) map[zapcore.Level]uint64,
+) {
+	for _, s := range stats {
+		c := counts(s)
+
+		for lvl := zapcore.DebugLevel; lvl <= zapcore.FatalLevel; lvl++ {
+			fmt.Fprintf(buf, "%s{logger=\"%s\",level=\"%s\"} %d\n",
+				metric, escapeMetricLabel(s.Name), lvl.String(), c[lvl])
+		}
+	}
+}
+
+// writeMetricHeader writes the HELP and TYPE lines for a metric.
+func writeMetricHeader(buf *bufio.Writer, metric, metricType, help string) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", metric, help, metric, metricType)
+}
+
+// escapeMetricLabel escapes a label value.
+func escapeMetricLabel(v string) string {
+	return metricsLabelReplacer.Replace(v)
+}
@@ -0,0 +1,106 @@
+package zaptool_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	fac, _ := observer.New(zapcore.DebugLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger, zapcore.InfoLevel)
+	named := loglvls.Named("Server.Process", zapcore.InfoLevel)
+	loglvls.Named(`Odd"Name`, zapcore.InfoLevel)
+
+	named.Debug("dropped")
+	named.Info("written")
+	named.Info("written")
+	loglvls.SetLevel("Server.*", zapcore.DebugLevel)
+
+	buf := &bytes.Buffer{}
+	if err := zaptool.WriteMetrics(buf, loglvls); err != nil {
+		t.Fatalf("WriteMetrics returned error: %s", err)
+	}
+
+	out := buf.String()
+
+	for _, expect := range []string{
+		"# TYPE zaptool_log_level gauge\n",
+		`zaptool_log_level{logger="Server.Process"} -1` + "\n",
+		`zaptool_log_level{logger="Odd\"Name"} 0` + "\n",
+		"# TYPE zaptool_log_entries_total counter\n",
+		`zaptool_log_entries_total{logger="Server.Process",level="info"} 2` + "\n",
+		`zaptool_log_entries_total{logger="Server.Process",level="error"} 0` + "\n",
+		`zaptool_log_entries_dropped_total{logger="Server.Process",level="debug"} 1` + "\n",
+		`zaptool_log_last_write_timestamp_seconds{logger="Server.Process"} `,
+	} {
+		if !strings.Contains(out, expect) {
+			t.Errorf("metrics should contain %q:\n%s", expect, out)
+		}
+	}
+
+	if strings.Contains(out, `zaptool_log_last_write_timestamp_seconds{logger="Odd\"Name"}`) {
+		t.Errorf("metrics should not contain a last write time for an unused logger:\n%s", out)
+	}
+}
+
+func TestWriteMetrics_SubLogLevels(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	sublvls := zaptool.NewSubLogLevels("Server", loglvls)
+	sublvls.Named("Process")
+
+	buf := &bytes.Buffer{}
+	if err := zaptool.WriteMetrics(buf, sublvls); err != nil {
+		t.Fatalf("WriteMetrics returned error: %s", err)
+	}
+
+	expect := "# HELP zaptool_log_level Current level of the logger (-1 debug, 0 info, 1 warn, 2 error, 3 dpanic, 4 panic, 5 fatal).\n" +
+		"# TYPE zaptool_log_level gauge\n" +
+		`zaptool_log_level{logger="Process"} 0` + "\n"
+
+	if buf.String() != expect {
+		t.Errorf("metrics: got\n%s\nwant\n%s", buf.String(), expect)
+	}
+}
+
+func TestMetricsHTTPHandler(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	handler := zaptool.MetricsHTTPHandler(loglvls)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type: got '%s'", ct)
+	}
+
+	if !strings.Contains(rec.Body.String(), `zaptool_log_level{logger="Internal.LogLevels"} 0`) {
+		t.Errorf("body should contain the internal level:\n%s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status: got %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
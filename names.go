@@ -0,0 +1,56 @@
+package zaptool
+
+import "strings"
+
+// NamePolicy defines how names and patterns are normalised before they are stored or
+// matched, it is passed as an option to NewLogLevels and applies to every method that
+// accepts a name or pattern.
+type NamePolicy int
+
+const (
+	// NameCaseSensitive stores names as they are given and matches wildcard patterns and
+	// regular expressions exactly, this is the default. Names without wildcards passed to
+	// SetLevel (and the other methods that take a name, eg. IsLogger, Stats and Sampling)
+	// still match regardless of case.
+	NameCaseSensitive NamePolicy = iota
+	// NameCaseInsensitive stores names in lower case and matches names and patterns
+	// regardless of case, regular expression patterns are matched case-insensitively.
+	NameCaseInsensitive
+)
+
+// normaliseName returns the name (or pattern) normalised according to the name policy.
+func (a *LogLevels) normaliseName(name string) string {
+	if a.namePolicy != NameCaseInsensitive || isRegexpPattern(name) {
+		return name
+	}
+
+	return strings.ToLower(name)
+}
+
+// lookupLevel returns the stored name and entry for the name, which is matched in the same
+// way as a name without wildcards passed to SetLevel (so regardless of case).
+//
+// Callers must hold the lock.
+func (a *LogLevels) lookupLevel(name string) (string, *levelEntry, bool) {
+	if v, ok := a.levels[name]; ok {
+		return name, v, true
+	}
+
+	for k, v := range a.levels {
+		if strings.EqualFold(k, name) {
+			return k, v, true
+		}
+	}
+
+	return "", nil, false
+}
+
+// normaliseRules returns a copy of the rules with the patterns normalised.
+func (a *LogLevels) normaliseRules(rules []LevelRule) []LevelRule {
+	out := make([]LevelRule, 0, len(rules))
+	for _, rule := range rules {
+		out = append(out, LevelRule{Pattern: a.normaliseName(rule.Pattern), Level: rule.Level})
+	}
+
+	return out
+}
@@ -0,0 +1,139 @@
+package zaptool_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogLevels_NameCaseSensitive(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.Named("Server.Process")
+
+	if loglvls.SetLevel("server.*", zapcore.DebugLevel) {
+		t.Error("SetLevel should be case-sensitive for wildcard patterns")
+	}
+
+	if !loglvls.SetLevel("/Server\\..*/", zapcore.WarnLevel) {
+		t.Error("SetLevel should match regular expressions")
+	}
+
+	if loglvls.SetLevel("/SERVER\\..*/", zapcore.WarnLevel) {
+		t.Error("SetLevel should be case-sensitive for regular expressions")
+	}
+
+	loglvls.NewLevel("server.process")
+
+	// server.process picks up the rules from the earlier SetLevel calls.
+	if loglvls.String() != "Internal.LogLevels:info,Server.Process:warn,server.process:debug" {
+		t.Errorf("names differing by case should be separate levels: %s", loglvls.String())
+	}
+}
+
+func TestLogLevels_ExactNameIgnoresCase(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.Named("TestLogger")
+	loglvls.Named("Other")
+
+	// exact names have always matched regardless of case.
+	if !loglvls.SetLevel("testlogger", zapcore.DebugLevel) {
+		t.Error("SetLevel should match exact names regardless of case")
+	}
+
+	if loglvls.String() != "Internal.LogLevels:info,Other:info,TestLogger:debug" {
+		t.Errorf("levels: got '%s'", loglvls.String())
+	}
+
+	loglvls.DeleteLevel("OTHER")
+
+	if loglvls.IsLogger("Other") {
+		t.Error("DeleteLevel should match exact names regardless of case")
+	}
+}
+
+func TestLogLevels_ExactNameMethodsAgree(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.Named("Server")
+	loglvls.SetSampling("Server", zaptool.SamplingPolicy{Tick: time.Second, First: 1, Thereafter: 10})
+
+	name := "sERVER"
+
+	if !loglvls.IsLogger(name) {
+		t.Errorf("IsLogger(%q) should match Server", name)
+	}
+
+	if stats, ok := loglvls.Stats(name); !ok || stats.Name != "Server" {
+		t.Errorf("Stats(%q) should return the stats of Server: %+v, %t", name, stats, ok)
+	}
+
+	if _, ok := loglvls.Sampling(name); !ok {
+		t.Errorf("Sampling(%q) should return the policy of Server", name)
+	}
+
+	if !loglvls.SetLevel(name, zapcore.WarnLevel) {
+		t.Errorf("SetLevel(%q) should match Server", name)
+	}
+
+	loglvls.DeleteLevel(name)
+
+	if loglvls.IsLogger("Server") {
+		t.Errorf("DeleteLevel(%q) should delete Server", name)
+	}
+}
+
+func TestLogLevels_NameCaseInsensitive(t *testing.T) {
+	fac, observedLogs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger, zaptool.NameCaseInsensitive)
+	process := loglvls.Named("Server.Process")
+
+	if !loglvls.IsLogger("SERVER.process") {
+		t.Error("IsLogger should be case-insensitive")
+	}
+
+	if loglvls.NewLevel("server.PROCESS") != loglvls.NewLevel("Server.Process") {
+		t.Error("NewLevel should return the same level regardless of case")
+	}
+
+	if !loglvls.SetLevel("SERVER.*", zapcore.DebugLevel) {
+		t.Error("SetLevel should be case-insensitive for wildcard patterns")
+	}
+
+	if !loglvls.SetLevel("/SERVER\\..*/", zapcore.WarnLevel) {
+		t.Error("SetLevel should be case-insensitive for regular expressions")
+	}
+
+	if !loglvls.SetLevel("server.process", zapcore.DebugLevel) {
+		t.Error("SetLevel should be case-insensitive for exact names")
+	}
+
+	if loglvls.String() != "internal.loglevels:info,server.process:debug" {
+		t.Errorf("names should be stored in lower case: %s", loglvls.String())
+	}
+
+	process.Debug("should log")
+
+	if logs := observedLogs.FilterMessage("should log").All(); len(logs) != 1 || logs[0].LoggerName != "Server.Process" {
+		t.Errorf("logger name should not be normalised: %v", logs)
+	}
+
+	loglvls.DeleteLevel("SERVER.PROCESS")
+
+	if loglvls.IsLogger("Server.Process") {
+		t.Error("DeleteLevel should be case-insensitive")
+	}
+}
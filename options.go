@@ -1,6 +1,10 @@
 package zaptool
 
-import "go.uber.org/zap/zapcore"
+import (
+	"net/http"
+
+	"go.uber.org/zap/zapcore"
+)
 
 type loggingOptions struct {
 	includeTiming        bool
@@ -47,3 +51,33 @@ func LoggingOptionLogLevel(level zapcore.Level) loggingOptionsFunc {
 		o.logLevel = level
 	}
 }
+
+type levelsHandlerOptions struct {
+	authorizer func(*http.Request) bool
+	actor      func(*http.Request) string
+}
+
+type levelsHandlerOptionsFunc func(o *levelsHandlerOptions)
+
+// LevelsHandlerOptionAuthorizer defines a function that is called to authorise requests
+// that change levels, if it returns false the request is rejected as forbidden.
+//
+// When not specified all requests are allowed, so anyone that can reach the handler can
+// change levels.
+//
+//nolint:revive // deliberately not-exported function type.
+func LevelsHandlerOptionAuthorizer(f func(*http.Request) bool) levelsHandlerOptionsFunc {
+	return func(o *levelsHandlerOptions) {
+		o.authorizer = f
+	}
+}
+
+// LevelsHandlerOptionActor defines a function that returns who made a request that changes
+// levels (eg. the authenticated user), it is recorded as the actor of the change.
+//
+//nolint:revive // deliberately not-exported function type.
+func LevelsHandlerOptionActor(f func(*http.Request) string) levelsHandlerOptionsFunc {
+	return func(o *levelsHandlerOptions) {
+		o.actor = f
+	}
+}
@@ -0,0 +1,191 @@
+package zaptool
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultReclaimInterval is the interval idle levels are checked at if the interval passed
+// to ReclaimIdleLevels is not positive.
+const defaultReclaimInterval = time.Minute
+
+// entryRef is the level entry used by the loggers created by a call to Named, if the
+// entry is reclaimed it is restored (or replaced by the entry now stored for the name)
+// the next time one of the loggers is used.
+type entryRef struct {
+	owner *LogLevels
+	name  string
+	entry atomic.Pointer[levelEntry]
+}
+
+// get returns the current entry and marks it as seen.
+func (r *entryRef) get() *levelEntry {
+	entry := r.entry.Load()
+	if entry.reclaimed.Load() {
+		entry = r.owner.revive(r)
+	}
+
+	if !entry.seen.Load() {
+		entry.seen.Store(true)
+	}
+
+	return entry
+}
+
+// revive stores the reclaimed entry of the ref again, if an entry has been created for
+// the name since it was reclaimed the ref uses that entry instead.
+func (a *LogLevels) revive(ref *entryRef) *levelEntry {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	entry := ref.entry.Load()
+	if !entry.reclaimed.Load() {
+		return entry
+	}
+
+	if current, ok := a.levels[ref.name]; ok {
+		ref.entry.Store(current)
+		return current
+	}
+
+	a.iLogger.Debug("reviving reclaimed level", zap.String("name", ref.name))
+
+	entry.lastSeen = time.Now()
+	entry.sampler.set(a.samplingPolicy(ref.name))
+	a.addEntry(ref.name, entry)
+	a.resetEntry(ref.name, entry, ref.name)
+	a.inheritFrom(ref.name, ref.name)
+	entry.reclaimed.Store(false)
+
+	return entry
+}
+
+// reclaimable returns true if the entry is not explicitly set, matched by a rule or pinned.
+//
+// Callers must hold the lock.
+func (a *LogLevels) reclaimable(name string, entry *levelEntry) bool {
+	if entry.explicit || entry.pinned {
+		return false
+	}
+
+	_, ok := a.ruleLevel(name)
+
+	return !ok
+}
+
+// ReclaimIdle removes the levels that have not been used by a logger for at least the idle
+// duration and are not explicitly set, matched by a rule or returned by NewLevel. It returns
+// the number of levels removed.
+//
+// A level is used when a logger created for it by Named checks or writes an entry (at any
+// level), use is only noticed by calls to ReclaimIdle so a level is removed by the first
+// call that is at least idle after the last call that noticed it was used.
+//
+// If a logger for a removed level is used again the level is recreated, so loggers that
+// are idle for a long time still follow later changes to their level.
+func (a *LogLevels) ReclaimIdle(idle time.Duration) int {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	now := time.Now()
+	count := 0
+
+	for name, entry := range a.levels {
+		if entry.seen.Swap(false) {
+			entry.lastSeen = now
+			continue
+		}
+
+		if now.Sub(entry.lastSeen) < idle || !a.reclaimable(name, entry) {
+			continue
+		}
+
+		entry.reclaimed.Store(true)
+		a.removeEntry(name)
+
+		count++
+
+		a.publish(LevelEvent{
+			Name:    name,
+			Old:     entry.level.Level(),
+			New:     zapcore.InvalidLevel,
+			Deleted: true,
+			Time:    now,
+		})
+	}
+
+	if count > 0 {
+		a.reclaimed.Add(uint64(count))
+		a.iLogger.Debug("reclaimed idle levels", zap.Int("count", count))
+	}
+
+	return count
+}
+
+// Reclaimed returns the total number of levels removed by ReclaimIdle.
+func (a *LogLevels) Reclaimed() uint64 {
+	return a.reclaimed.Load()
+}
+
+// LevelReclaimer calls ReclaimIdle on a LogLevels at an interval.
+type LevelReclaimer struct {
+	ll       *LogLevels
+	idle     time.Duration
+	interval time.Duration
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// ReclaimIdleLevels removes the levels that have been idle for the idle duration (see
+// ReclaimIdle) from the LogLevels, checking at the interval until Stop is called.
+//
+// If the interval is not positive (eg. unset in a config file) the levels are checked every
+// minute.
+func ReclaimIdleLevels(ll *LogLevels, idle, interval time.Duration) *LevelReclaimer {
+	if interval <= 0 {
+		interval = defaultReclaimInterval
+	}
+
+	r := &LevelReclaimer{
+		ll:       ll,
+		idle:     idle,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	go r.run()
+
+	return r
+}
+
+// run reclaims idle levels until the reclaimer is stopped.
+func (r *LevelReclaimer) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.ll.ReclaimIdle(r.idle)
+		}
+	}
+}
+
+// Stop stops reclaiming idle levels.
+func (r *LevelReclaimer) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+
+	<-r.done
+}
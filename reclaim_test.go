@@ -0,0 +1,140 @@
+package zaptool_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogLevels_ReclaimIdle(t *testing.T) {
+	fac, observedLogs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger, zapcore.InfoLevel)
+	loglvls.SetLevel("Configured", zapcore.DebugLevel)
+
+	idle := loglvls.Named("Tenant.Idle")
+	used := loglvls.Named("Tenant.Used")
+	loglvls.Named("Configured")
+	loglvls.NewLevel("Pinned")
+
+	events, unsubscribe := loglvls.Subscribe(10)
+	defer unsubscribe()
+
+	used.Info("used")
+
+	if n := loglvls.ReclaimIdle(time.Hour); n != 0 {
+		t.Errorf("ReclaimIdle should not remove levels used within the idle duration, removed %d", n)
+	}
+
+	used.Info("used")
+
+	if n := loglvls.ReclaimIdle(0); n != 1 {
+		t.Errorf("ReclaimIdle should remove 1 idle level, removed %d", n)
+	}
+
+	if loglvls.String() != "Configured:debug,Internal.LogLevels:info,Pinned:info,Tenant.Used:debug" {
+		t.Errorf("levels: got '%s'", loglvls.String())
+	}
+
+	select {
+	case ev := <-events:
+		if ev.Name != "Tenant.Idle" || !ev.Deleted {
+			t.Errorf("reclaimed level should publish a deleted event: %+v", ev)
+		}
+	default:
+		t.Error("reclaimed level should publish a deleted event")
+	}
+
+	if n := loglvls.ReclaimIdle(0); n != 1 || loglvls.IsLogger("Tenant.Used") {
+		t.Errorf("ReclaimIdle should remove the level once it is idle, removed %d", n)
+	}
+
+	if loglvls.Reclaimed() != 2 {
+		t.Errorf("Reclaimed: got %d, want 2", loglvls.Reclaimed())
+	}
+
+	loglvls.SetLevel("Tenant.*", zapcore.WarnLevel)
+	observedLogs.TakeAll()
+
+	idle.Info("should not log")
+	idle.Warn("should log")
+
+	if logs := observedLogs.TakeAll(); len(logs) != 1 || logs[0].Message != "should log" {
+		t.Errorf("reclaimed logger should be revived at the rule level: %v", logs)
+	}
+
+	if !loglvls.IsLogger("Tenant.Idle") {
+		t.Error("using a reclaimed logger should recreate its level")
+	}
+
+	buf := &bytes.Buffer{}
+	_ = zaptool.WriteMetrics(buf, loglvls)
+
+	if !strings.Contains(buf.String(), "zaptool_log_levels_reclaimed_total 2\n") {
+		t.Errorf("metrics should contain the reclaimed count:\n%s", buf.String())
+	}
+}
+
+func TestLogLevels_ReclaimIdleReplaced(t *testing.T) {
+	fac, observedLogs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	old := loglvls.Named("Tenant.Conn")
+
+	loglvls.ReclaimIdle(0)
+
+	replacement := loglvls.Named("Tenant.Conn")
+	loglvls.SetLevel("Tenant.Conn", zapcore.ErrorLevel)
+
+	old.Warn("should not log")
+	replacement.Warn("should not log")
+	old.Error("should log")
+
+	if logs := observedLogs.FilterLoggerName("Tenant.Conn").All(); len(logs) != 1 || logs[0].Message != "should log" {
+		t.Errorf("reclaimed logger should follow the replacement level: %v", logs)
+	}
+
+	if stats, _ := loglvls.Stats("Tenant.Conn"); stats.TotalWritten() != 1 || stats.TotalDropped() != 2 {
+		t.Errorf("reclaimed logger should count entries on the replacement level: %+v", stats)
+	}
+}
+
+func TestReclaimIdleLevels(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.Named("Tenant.Idle")
+
+	reclaimer := zaptool.ReclaimIdleLevels(loglvls, 0, time.Millisecond)
+	defer reclaimer.Stop()
+
+	deadline := time.Now().Add(time.Second)
+	for loglvls.IsLogger("Tenant.Idle") && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if loglvls.IsLogger("Tenant.Idle") {
+		t.Error("reclaimer should remove the idle level")
+	}
+
+	if !loglvls.IsLogger("Internal.LogLevels") {
+		t.Error("reclaimer should not remove the internal level")
+	}
+}
+
+func TestReclaimIdleLevels_ZeroInterval(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	reclaimer := zaptool.ReclaimIdleLevels(zaptool.NewLogLevels(logger), time.Hour, 0)
+	reclaimer.Stop()
+}
@@ -0,0 +1,122 @@
+package zaptool
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ErrInvalidRoute is returned when a route is added without a core.
+var ErrInvalidRoute = errors.New("invalid route")
+
+// RouteMode defines whether the entries of a routed logger are also written to the core
+// of the logger passed to NewLogLevels.
+type RouteMode int
+
+const (
+	// RouteAdditional writes the entries to the route core as well as the core of the
+	// logger passed to NewLogLevels.
+	RouteAdditional RouteMode = iota
+	// RouteExclusive writes the entries only to the route core(s).
+	RouteExclusive
+)
+
+// Route sends the entries of loggers matching the name (or wildcard pattern) to an
+// additional or alternative core.
+type Route struct {
+	Pattern string
+	Core    zapcore.Core
+	Mode    RouteMode
+}
+
+// AddRoute sends the entries of loggers matching the name (or wildcard pattern) to the
+// core, every matching route is used and if any of them is RouteExclusive the entries are
+// not written to the core of the logger passed to NewLogLevels.
+//
+// Routes are resolved when a logger is created by Named, so loggers created before the
+// route was added are not changed. The named level decides which entries are logged, the
+// level of the route core is also applied.
+func (a *LogLevels) AddRoute(name string, core zapcore.Core, mode RouteMode) error {
+	name = a.normaliseName(name)
+
+	if err := ValidatePattern(name); err != nil {
+		return err
+	}
+
+	if core == nil {
+		return fmt.Errorf("%w: no core for %q", ErrInvalidRoute, name)
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.routes = append(a.routes, Route{Pattern: name, Core: core, Mode: mode})
+
+	return nil
+}
+
+// AddRouteWriter sends the entries of loggers matching the name (or wildcard pattern) to
+// the WriteSyncer encoded as JSON with the zap production encoder config, see AddRoute.
+func (a *LogLevels) AddRouteWriter(name string, ws zapcore.WriteSyncer, mode RouteMode) error {
+	enc := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+
+	return a.AddRoute(name, zapcore.NewCore(enc, ws, zapcore.DebugLevel), mode)
+}
+
+// RemoveRoutes removes the routes added for the name (or wildcard pattern), loggers
+// already created keep writing to the route. It returns true if any routes were removed.
+func (a *LogLevels) RemoveRoutes(name string) bool {
+	name = a.normaliseName(name)
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	out := a.routes[:0]
+
+	for _, route := range a.routes {
+		if route.Pattern != name {
+			out = append(out, route)
+		}
+	}
+
+	removed := len(out) != len(a.routes)
+	a.routes = out
+
+	return removed
+}
+
+// Routes returns a copy of the routes in the order they were added.
+func (a *LogLevels) Routes() []Route {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	out := make([]Route, len(a.routes))
+	copy(out, a.routes)
+
+	return out
+}
+
+// routeCores returns the core for the logger to write to and the route cores that match
+// the name, the core is a no-op core if an exclusive route matches.
+func (a *LogLevels) routeCores(name string, base zapcore.Core) (zapcore.Core, []zapcore.Core) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	var routes []zapcore.Core
+
+	for _, route := range a.routes {
+		if !a.doesKeyMatch(name, route.Pattern) {
+			continue
+		}
+
+		routes = append(routes, route.Core)
+
+		if route.Mode == RouteExclusive {
+			base = zapcore.NewNopCore()
+		}
+	}
+
+	return base, routes
+}
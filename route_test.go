@@ -0,0 +1,102 @@
+package zaptool_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogLevels_AddRoute(t *testing.T) {
+	fac, observedLogs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	auditFac, auditLogs := observer.New(zapcore.DebugLevel)
+	securityFac, securityLogs := observer.New(zapcore.WarnLevel)
+
+	loglvls := zaptool.NewLogLevels(logger)
+
+	if err := loglvls.AddRoute("Audit.*", auditFac, zaptool.RouteExclusive); err != nil {
+		t.Fatalf("AddRoute returned error: %s", err)
+	}
+
+	if err := loglvls.AddRoute("**.Security", securityFac, zaptool.RouteAdditional); err != nil {
+		t.Fatalf("AddRoute returned error: %s", err)
+	}
+
+	loglvls.Named("Audit.Login").With(zap.String("user", "bob")).Info("audit")
+	loglvls.Named("Server.Security").Info("below route level")
+	loglvls.Named("Server.Security").Warn("security")
+	loglvls.Named("Server.Process").Info("process")
+
+	if logs := auditLogs.TakeAll(); len(logs) != 1 || logs[0].Message != "audit" || logs[0].ContextMap()["user"] != "bob" {
+		t.Errorf("audit route should contain the audit entry with fields: %v", logs)
+	}
+
+	if logs := securityLogs.TakeAll(); len(logs) != 1 || logs[0].Message != "security" {
+		t.Errorf("security route should only contain entries at or above its level: %v", logs)
+	}
+
+	messages := []string{}
+	for _, le := range observedLogs.TakeAll() {
+		messages = append(messages, le.Message)
+	}
+
+	if strings.Join(messages, ",") != "below route level,security,process" {
+		t.Errorf("core should not contain entries for exclusive routes: %v", messages)
+	}
+
+	if routes := loglvls.Routes(); len(routes) != 2 || routes[0].Pattern != "Audit.*" {
+		t.Errorf("Routes: got %v", routes)
+	}
+
+	if !loglvls.RemoveRoutes("Audit.*") {
+		t.Error("RemoveRoutes should return true when routes are removed")
+	}
+
+	if loglvls.RemoveRoutes("Audit.*") {
+		t.Error("RemoveRoutes should return false when no routes are removed")
+	}
+
+	loglvls.Named("Audit.Logout").Info("audit")
+
+	if auditLogs.Len() != 0 || observedLogs.Len() != 1 {
+		t.Errorf("loggers created after RemoveRoutes should not be routed")
+	}
+}
+
+func TestLogLevels_AddRouteWriter(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	buf := &bytes.Buffer{}
+
+	if err := loglvls.AddRouteWriter("Audit", zapcore.AddSync(buf), zaptool.RouteExclusive); err != nil {
+		t.Fatalf("AddRouteWriter returned error: %s", err)
+	}
+
+	named := loglvls.Named("Audit", zapcore.DebugLevel)
+	named.Debug("written")
+
+	if err := named.Sync(); err != nil {
+		t.Errorf("Sync returned error: %s", err)
+	}
+
+	if !strings.Contains(buf.String(), `"logger":"Audit","msg":"written"`) {
+		t.Errorf("writer should contain the entry: %s", buf.String())
+	}
+
+	if err := loglvls.AddRoute("Audit", nil, zaptool.RouteAdditional); !errors.Is(err, zaptool.ErrInvalidRoute) {
+		t.Errorf("AddRoute should return ErrInvalidRoute for a nil core: %v", err)
+	}
+
+	if err := loglvls.AddRoute("/(/", fac, zaptool.RouteAdditional); !errors.Is(err, zaptool.ErrInvalidPattern) {
+		t.Errorf("AddRoute should return ErrInvalidPattern for an invalid pattern: %v", err)
+	}
+}
@@ -0,0 +1,270 @@
+package zaptool
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ErrInvalidSamplingPolicy is returned when a sampling policy has a tick that is not
+// positive or negative counts.
+var ErrInvalidSamplingPolicy = errors.New("invalid sampling policy")
+
+// samplerCounters is the number of counters kept per level, entries are assigned to a
+// counter by a hash of their message.
+const samplerCounters = 256
+
+// SamplingPolicy logs the first entries with the same level and message in each tick and
+// every Thereafter entry after that, the same as zapcore.NewSamplerWithOptions.
+//
+// If Thereafter is zero only the First entries in each tick are logged.
+type SamplingPolicy struct {
+	Tick       time.Duration `json:"tick"`
+	First      int           `json:"first"`
+	Thereafter int           `json:"thereafter"`
+}
+
+// Validate returns an error wrapping ErrInvalidSamplingPolicy if the policy is not valid.
+func (p SamplingPolicy) Validate() error {
+	if p.Tick <= 0 || p.First < 0 || p.Thereafter < 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidSamplingPolicy, p.String())
+	}
+
+	return nil
+}
+
+// String returns the policy in the form "first/thereafter per tick".
+func (p SamplingPolicy) String() string {
+	return fmt.Sprintf("%d/%d per %s", p.First, p.Thereafter, p.Tick)
+}
+
+// SamplingRule is a sampling policy set by SetSampling for a name or wildcard pattern.
+type SamplingRule struct {
+	Pattern string         `json:"pattern"`
+	Policy  SamplingPolicy `json:"policy"`
+}
+
+// samplerCounter counts the entries for a level and message in the current tick.
+type samplerCounter struct {
+	resetAt atomic.Int64
+	counter atomic.Uint64
+}
+
+// incCheckReset increments the counter, resetting it first if the tick has passed.
+func (c *samplerCounter) incCheckReset(t time.Time, tick time.Duration) uint64 {
+	tn := t.UnixNano()
+
+	resetAfter := c.resetAt.Load()
+	if resetAfter > tn {
+		return c.counter.Add(1)
+	}
+
+	c.counter.Store(1)
+
+	if !c.resetAt.CompareAndSwap(resetAfter, tn+tick.Nanoseconds()) {
+		return c.counter.Add(1)
+	}
+
+	return 1
+}
+
+// samplerState is the policy and counters of an entrySampler, the counters are reset
+// whenever the policy is changed.
+type samplerState struct {
+	policy   SamplingPolicy
+	counters [statsLevelCount][samplerCounters]samplerCounter
+}
+
+// entrySampler samples the entries of a named level, it is shared by every logger
+// created for the name.
+type entrySampler struct {
+	state atomic.Pointer[samplerState]
+}
+
+// set changes the policy, a nil policy disables sampling.
+func (s *entrySampler) set(policy *SamplingPolicy) {
+	if policy == nil {
+		s.state.Store(nil)
+		return
+	}
+
+	if current := s.state.Load(); current != nil && current.policy == *policy {
+		return
+	}
+
+	s.state.Store(&samplerState{policy: *policy})
+}
+
+// policy returns the current policy, it returns false if sampling is disabled.
+func (s *entrySampler) policy() (SamplingPolicy, bool) {
+	if current := s.state.Load(); current != nil {
+		return current.policy, true
+	}
+
+	return SamplingPolicy{}, false
+}
+
+// sample returns true if the entry should be logged.
+func (s *entrySampler) sample(ent zapcore.Entry) bool {
+	state := s.state.Load()
+	if state == nil {
+		return true
+	}
+
+	idx, ok := statsIndex(ent.Level)
+	if !ok {
+		return true
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(ent.Message))
+
+	t := ent.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	n := state.counters[idx][hash.Sum32()%samplerCounters].incCheckReset(t, state.policy.Tick)
+	first := uint64(state.policy.First)
+
+	if n <= first {
+		return true
+	}
+
+	return state.policy.Thereafter > 0 && (n-first)%uint64(state.policy.Thereafter) == 0
+}
+
+// SetSampling sets the sampling policy for the name (or wildcard pattern), replacing any
+// policy previously set for the same pattern. Loggers created later that match the
+// pattern are also sampled.
+//
+// If more than one pattern matches a name the most specific one is used (in the same way
+// as level rules), sampling is not inherited by child names. It returns true if any
+// existing levels matched.
+func (a *LogLevels) SetSampling(name string, policy SamplingPolicy) bool {
+	name = a.normaliseName(name)
+	a.iLogger.Debug("SetSampling", zap.String("name", name), zap.Stringer("policy", policy))
+
+	if err := ValidatePattern(name); err != nil {
+		a.iLogger.Debug("invalid pattern", zap.String("name", name), zap.Error(err))
+		return false
+	}
+
+	if err := policy.Validate(); err != nil {
+		a.iLogger.Debug("invalid sampling policy", zap.String("name", name), zap.Error(err))
+		return false
+	}
+
+	a.lock.Lock()
+	a.removeSamplingRule(name)
+	a.sampling = append(a.sampling, SamplingRule{Pattern: name, Policy: policy})
+	found := a.refreshSampling(name)
+	pending := a.persist()
+	a.lock.Unlock()
+
+	pending.save()
+
+	return found
+}
+
+// ClearSampling removes the sampling policy set for the name (or wildcard pattern),
+// matching levels are sampled by the next most specific policy, if there is one.
+// It returns true if any existing levels matched.
+func (a *LogLevels) ClearSampling(name string) bool {
+	name = a.normaliseName(name)
+	a.iLogger.Debug("ClearSampling", zap.String("name", name))
+
+	a.lock.Lock()
+	a.removeSamplingRule(name)
+	found := a.refreshSampling(name)
+	pending := a.persist()
+	a.lock.Unlock()
+
+	pending.save()
+
+	return found
+}
+
+// Sampling returns the sampling policy used by the named level, it returns false if the
+// level does not exist or is not sampled. Names are matched regardless of case as they are
+// by SetLevel.
+func (a *LogLevels) Sampling(name string) (SamplingPolicy, bool) {
+	name = a.normaliseName(name)
+
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	if _, v, ok := a.lookupLevel(name); ok {
+		return v.sampler.policy()
+	}
+
+	return SamplingPolicy{}, false
+}
+
+// SamplingRules returns a copy of the stored sampling policies in the order they were set.
+func (a *LogLevels) SamplingRules() []SamplingRule {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	out := make([]SamplingRule, len(a.sampling))
+	copy(out, a.sampling)
+
+	return out
+}
+
+// removeSamplingRule removes the sampling policy with the pattern if it exists.
+//
+// Callers must hold the lock.
+func (a *LogLevels) removeSamplingRule(pattern string) {
+	for idx, rule := range a.sampling {
+		if rule.Pattern == pattern {
+			a.sampling = append(a.sampling[:idx], a.sampling[idx+1:]...)
+			return
+		}
+	}
+}
+
+// refreshSampling updates the sampling policy of every level, it returns true if any
+// level matches the pattern.
+//
+// Callers must hold the lock.
+func (a *LogLevels) refreshSampling(pattern string) bool {
+	found := false
+
+	for k, v := range a.levels {
+		if a.doesKeyMatch(k, pattern) {
+			found = true
+		}
+
+		v.sampler.set(a.samplingPolicy(k))
+	}
+
+	return found
+}
+
+// samplingPolicy returns the most specific sampling policy that matches the name, or nil
+// if the name is not sampled.
+//
+// Callers must hold the lock.
+func (a *LogLevels) samplingPolicy(name string) *SamplingPolicy {
+	var out *SamplingPolicy
+
+	best := regexpSpecificity
+
+	for idx, rule := range a.sampling {
+		m, err := a.matchers.get(rule.Pattern)
+		if err != nil || !m.match(name) || m.specificity < best {
+			continue
+		}
+
+		best = m.specificity
+		out = &a.sampling[idx].Policy
+	}
+
+	return out
+}
@@ -0,0 +1,147 @@
+package zaptool_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type samplingTestClock struct {
+	now time.Time
+}
+
+func (c *samplingTestClock) Now() time.Time {
+	return c.now
+}
+
+func (c *samplingTestClock) NewTicker(d time.Duration) *time.Ticker {
+	return time.NewTicker(d)
+}
+
+func TestLogLevels_SetSampling(t *testing.T) {
+	clock := &samplingTestClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	fac, observedLogs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac, zap.WithClock(clock))
+
+	loglvls := zaptool.NewLogLevels(logger)
+	hot := loglvls.Named("Server.Hot")
+	quiet := loglvls.Named("Server.Quiet")
+
+	if !loglvls.SetSampling("Server.Hot", zaptool.SamplingPolicy{Tick: time.Second, First: 2, Thereafter: 3}) {
+		t.Error("SetSampling should return true when levels match")
+	}
+
+	for i := 0; i < 10; i++ {
+		hot.Info("hot path")
+		quiet.Info("quiet path")
+	}
+
+	hot.Info("other message")
+
+	// first 2, then every 3rd (5th and 8th) of the same message.
+	if n := observedLogs.FilterMessage("hot path").Len(); n != 4 {
+		t.Errorf("sampled logger should log 4 messages, instead logged %d", n)
+	}
+
+	if n := observedLogs.FilterMessage("other message").Len(); n != 1 {
+		t.Errorf("messages should be sampled separately, instead logged %d", n)
+	}
+
+	if n := observedLogs.FilterMessage("quiet path").Len(); n != 10 {
+		t.Errorf("unsampled logger should log every message, instead logged %d", n)
+	}
+
+	stats, _ := loglvls.Stats("Server.Hot")
+	if stats.Sampled[zapcore.InfoLevel] != 6 || stats.Written[zapcore.InfoLevel] != 5 {
+		t.Errorf("stats: got %+v", stats)
+	}
+
+	observedLogs.TakeAll()
+	clock.now = clock.now.Add(time.Second)
+
+	hot.Info("hot path")
+	hot.Info("hot path")
+
+	if n := observedLogs.FilterMessage("hot path").Len(); n != 2 {
+		t.Errorf("counters should reset after the tick, instead logged %d", n)
+	}
+
+	observedLogs.TakeAll()
+
+	if !loglvls.ClearSampling("Server.Hot") {
+		t.Error("ClearSampling should return true when levels match")
+	}
+
+	for i := 0; i < 5; i++ {
+		hot.Info("hot path")
+	}
+
+	if n := observedLogs.FilterMessage("hot path").Len(); n != 5 {
+		t.Errorf("logger should not be sampled after ClearSampling, instead logged %d", n)
+	}
+}
+
+func TestLogLevels_SamplingRules(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+
+	wide := zaptool.SamplingPolicy{Tick: time.Second, First: 100, Thereafter: 100}
+	narrow := zaptool.SamplingPolicy{Tick: time.Second, First: 1, Thereafter: 0}
+
+	if loglvls.SetSampling("Server.**", wide) {
+		t.Error("SetSampling should return false when no levels match")
+	}
+
+	loglvls.SetSampling("Server.DB", narrow)
+	loglvls.Named("Server.DB")
+	loglvls.Named("Server.Process")
+	loglvls.Named("Client")
+
+	tests := []struct {
+		name   string
+		policy zaptool.SamplingPolicy
+		ok     bool
+	}{
+		{"Server.DB", narrow, true},
+		{"Server.Process", wide, true},
+		{"Client", zaptool.SamplingPolicy{}, false},
+		{"Missing", zaptool.SamplingPolicy{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, ok := loglvls.Sampling(tt.name)
+			if ok != tt.ok || policy != tt.policy {
+				t.Errorf("Sampling: got %s (%t), want %s (%t)", policy, ok, tt.policy, tt.ok)
+			}
+		})
+	}
+
+	if rules := loglvls.SamplingRules(); len(rules) != 2 || rules[0].Pattern != "Server.**" {
+		t.Errorf("SamplingRules: got %v", rules)
+	}
+
+	if loglvls.SetSampling("Client", zaptool.SamplingPolicy{}) {
+		t.Error("SetSampling should return false for an invalid policy")
+	}
+
+	if err := (zaptool.SamplingPolicy{Tick: time.Second, First: -1}).Validate(); !errors.Is(err, zaptool.ErrInvalidSamplingPolicy) {
+		t.Errorf("Validate should return ErrInvalidSamplingPolicy: %v", err)
+	}
+
+	snapshot := loglvls.Snapshot()
+	loglvls.ClearSampling("Server.DB")
+
+	loglvls.Restore(snapshot)
+
+	if policy, ok := loglvls.Sampling("Server.DB"); !ok || policy != narrow {
+		t.Errorf("Restore should restore the sampling policies: %s (%t)", policy, ok)
+	}
+}
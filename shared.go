@@ -0,0 +1,266 @@
+package zaptool
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultShareInterval is the interval a shared store is polled at if the interval passed
+// to ShareLevelStore is not positive.
+const defaultShareInterval = 10 * time.Second
+
+// LevelStoreVersioner is implemented by a LevelStore that can report a version that changes
+// whenever the stored snapshot changes, it lets a LevelStoreSync skip loading an unchanged
+// snapshot.
+type LevelStoreVersioner interface {
+	// Version returns the current version of the stored snapshot, the error wraps
+	// fs.ErrNotExist if nothing has been stored yet.
+	Version() (string, error)
+}
+
+// Version returns a hash of the contents of the file, the modification time is not used as
+// it may not change between two saves on file systems with a coarse resolution.
+func (s *FileStore) Version() (string, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read level store: %w", err)
+	}
+
+	hash := fnv.New64a()
+	_, _ = hash.Write(data)
+
+	return strconv.FormatUint(hash.Sum64(), 16), nil
+}
+
+// MemoryStore is a LevelStore that keeps the snapshot in memory, it can be shared by the
+// LogLevels in a single process.
+type MemoryStore struct {
+	lock     sync.Mutex
+	snapshot *LevelSnapshot
+	version  uint64
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Load returns the stored snapshot.
+func (s *MemoryStore) Load() (LevelSnapshot, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.snapshot == nil {
+		return LevelSnapshot{}, fmt.Errorf("unable to read level store: %w", fs.ErrNotExist)
+	}
+
+	return copySnapshot(*s.snapshot), nil
+}
+
+// Save stores a copy of the snapshot.
+func (s *MemoryStore) Save(snapshot LevelSnapshot) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	snapshot = copySnapshot(snapshot)
+	s.snapshot = &snapshot
+	s.version++
+
+	return nil
+}
+
+// Version returns the number of times the snapshot has been saved.
+func (s *MemoryStore) Version() (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.snapshot == nil {
+		return "", fmt.Errorf("unable to read level store: %w", fs.ErrNotExist)
+	}
+
+	return strconv.FormatUint(s.version, 10), nil
+}
+
+// copySnapshot returns a copy of the snapshot that does not share slices.
+func copySnapshot(snapshot LevelSnapshot) LevelSnapshot {
+	return LevelSnapshot{
+		Levels:   append([]SnapshotLevel(nil), snapshot.Levels...),
+		Rules:    append([]LevelRule(nil), snapshot.Rules...),
+		Sampling: append([]SamplingRule(nil), snapshot.Sampling...),
+	}
+}
+
+// sharedStore is the LevelStore used by a LogLevels that shares its rules, only the rules
+// and sampling policies are saved as the named levels differ between processes.
+type sharedStore struct {
+	LevelStore
+}
+
+// Save stores the snapshot without the named levels.
+func (s sharedStore) Save(snapshot LevelSnapshot) error {
+	snapshot.Levels = nil
+
+	return s.LevelStore.Save(snapshot) //nolint:wrapcheck // errors are already wrapped by the store.
+}
+
+// LevelStoreSync shares the rules of a LogLevels with the other LogLevels (usually in other
+// processes) using the same LevelStore.
+type LevelStoreSync struct {
+	ll       *LogLevels
+	store    LevelStore
+	interval time.Duration
+	lock     sync.Mutex
+	version  string
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// ShareLevelStore shares the rules and sampling policies of the LogLevels through the store,
+// changes made by SetLevel (and the other methods that change rules) are saved to the store
+// and the store is polled at the interval for changes made by other processes until Stop
+// is called.
+//
+// If the store already holds a snapshot its rules replace the rules of the LogLevels,
+// otherwise the current rules are saved. When the rules change every level is reset to
+// the level of its most specific rule (or inherits, see ClearLevel), levels set only by a
+// level passed to Named are not kept. If two processes change the rules at the same time
+// the last one saved wins.
+//
+// If the interval is not positive (eg. unset in a config file) the store is polled every
+// 10 seconds.
+func ShareLevelStore(ll *LogLevels, store LevelStore, interval time.Duration) *LevelStoreSync {
+	if interval <= 0 {
+		interval = defaultShareInterval
+	}
+
+	s := &LevelStoreSync{
+		ll:       ll,
+		store:    store,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	err := s.Sync()
+
+	var pending pendingSave
+
+	ll.lock.Lock()
+	ll.store = sharedStore{store}
+
+	if errors.Is(err, fs.ErrNotExist) {
+		pending = ll.persist()
+	}
+
+	ll.lock.Unlock()
+
+	pending.save()
+
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		ll.iLogger.Warn("unable to load shared level store", zap.Error(err))
+	}
+
+	go s.run()
+
+	return s
+}
+
+// run polls the store until the sync is stopped.
+func (s *LevelStoreSync) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.Sync(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				s.ll.iLogger.Warn("unable to load shared level store", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Sync loads the snapshot from the store and applies its rules if they differ from the
+// current rules, if the store reports a version the snapshot is only loaded when the
+// version changes.
+func (s *LevelStoreSync) Sync() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	version := ""
+
+	if v, ok := s.store.(LevelStoreVersioner); ok {
+		var err error
+
+		if version, err = v.Version(); err != nil {
+			return fmt.Errorf("unable to check shared level store: %w", err)
+		}
+
+		if version == s.version {
+			return nil
+		}
+	}
+
+	snapshot, err := s.store.Load()
+	if err != nil {
+		return fmt.Errorf("unable to load shared level store: %w", err)
+	}
+
+	s.version = version
+
+	if s.ll.sameRules(snapshot) {
+		return nil
+	}
+
+	snapshot.Levels = nil
+	s.ll.RestoreBy(snapshot, ChangeSource{Origin: "store", Reason: "shared level store changed"})
+
+	return nil
+}
+
+// Stop stops polling the store, changes are still saved to it.
+func (s *LevelStoreSync) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+
+	<-s.done
+}
+
+// sameRules returns true if the rules and sampling policies in the snapshot are the same as
+// the current ones.
+func (a *LogLevels) sameRules(snapshot LevelSnapshot) bool {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	if len(snapshot.Rules) != len(a.rules) || len(snapshot.Sampling) != len(a.sampling) {
+		return false
+	}
+
+	for idx, rule := range a.normaliseRules(snapshot.Rules) {
+		if rule != a.rules[idx] {
+			return false
+		}
+	}
+
+	for idx, rule := range snapshot.Sampling {
+		if a.normaliseName(rule.Pattern) != a.sampling[idx].Pattern || rule.Policy != a.sampling[idx].Policy {
+			return false
+		}
+	}
+
+	return true
+}
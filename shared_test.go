@@ -0,0 +1,149 @@
+package zaptool_test
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestShareLevelStore(t *testing.T) {
+	stores := map[string]func(t *testing.T) zaptool.LevelStore{
+		"FileStore": func(t *testing.T) zaptool.LevelStore {
+			t.Helper()
+			return zaptool.NewFileStore(filepath.Join(t.TempDir(), "levels.json"))
+		},
+		"MemoryStore": func(t *testing.T) zaptool.LevelStore {
+			t.Helper()
+			return zaptool.NewMemoryStore()
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			fac, _ := observer.New(zapcore.InfoLevel)
+			logger := zap.New(fac)
+
+			store := newStore(t)
+
+			first := zaptool.NewLogLevels(logger)
+			first.Named("Server.Process")
+			first.SetLevel("Server.*", zapcore.WarnLevel)
+
+			firstSync := zaptool.ShareLevelStore(first, store, time.Hour)
+			defer firstSync.Stop()
+
+			second := zaptool.NewLogLevels(logger)
+			second.Named("Worker.Queue")
+			second.Named("Server.Process")
+
+			secondSync := zaptool.ShareLevelStore(second, store, time.Hour)
+			defer secondSync.Stop()
+
+			if second.String() != "Internal.LogLevels:info,Server.Process:warn,Worker.Queue:info" {
+				t.Errorf("existing rules should be loaded from the store: %s", second.String())
+			}
+
+			second.SetLevel("Worker.*", zapcore.DebugLevel)
+
+			if err := firstSync.Sync(); err != nil {
+				t.Errorf("Sync returned error: %s", err)
+			}
+
+			if rules := first.Rules(); len(rules) != 2 || rules[1].String() != "Worker.*:debug" {
+				t.Errorf("rules should be synced from the store: %v", rules)
+			}
+
+			first.Named("Worker.Queue")
+
+			if first.String() != "Internal.LogLevels:info,Server.Process:warn,Worker.Queue:debug" {
+				t.Errorf("levels: got '%s'", first.String())
+			}
+
+			first.ClearLevel("Server.*")
+
+			if err := secondSync.Sync(); err != nil {
+				t.Errorf("Sync returned error: %s", err)
+			}
+
+			if second.String() != "Internal.LogLevels:info,Server.Process:info,Worker.Queue:debug" {
+				t.Errorf("cleared rules should be synced from the store: %s", second.String())
+			}
+
+			snapshot, err := store.Load()
+			if err != nil {
+				t.Fatalf("Load returned error: %s", err)
+			}
+
+			if len(snapshot.Levels) != 0 {
+				t.Errorf("named levels should not be saved to a shared store: %v", snapshot.Levels)
+			}
+		})
+	}
+}
+
+func TestShareLevelStore_Poll(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	store := zaptool.NewMemoryStore()
+
+	first := zaptool.NewLogLevels(logger)
+	second := zaptool.NewLogLevels(logger)
+	second.Named("Server")
+
+	firstSync := zaptool.ShareLevelStore(first, store, time.Hour)
+	defer firstSync.Stop()
+
+	secondSync := zaptool.ShareLevelStore(second, store, time.Millisecond)
+	defer secondSync.Stop()
+
+	first.SetLevel("Server", zapcore.ErrorLevel)
+
+	deadline := time.Now().Add(time.Second)
+	for second.String() != "Internal.LogLevels:info,Server:error" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if second.String() != "Internal.LogLevels:info,Server:error" {
+		t.Errorf("changes should be picked up by polling: %s", second.String())
+	}
+}
+
+func TestShareLevelStore_ZeroInterval(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	sync := zaptool.ShareLevelStore(zaptool.NewLogLevels(logger), zaptool.NewMemoryStore(), 0)
+	sync.Stop()
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := zaptool.NewMemoryStore()
+
+	if _, err := store.Load(); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Load should return fs.ErrNotExist for an empty store: %v", err)
+	}
+
+	rules := []zaptool.LevelRule{{Pattern: "Server.*", Level: zapcore.DebugLevel}}
+	if err := store.Save(zaptool.LevelSnapshot{Rules: rules}); err != nil {
+		t.Fatalf("Save returned error: %s", err)
+	}
+
+	rules[0].Level = zapcore.ErrorLevel
+
+	snapshot, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %s", err)
+	}
+
+	if len(snapshot.Rules) != 1 || snapshot.Rules[0].String() != "Server.*:debug" {
+		t.Errorf("Load should return a copy of the saved snapshot: %v", snapshot.Rules)
+	}
+}
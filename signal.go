@@ -0,0 +1,143 @@
+package zaptool
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DebugSignalHandler sets every logger to debug when a signal is received and restores the
+// previous state when another (or the same) signal is received.
+type DebugSignalHandler struct {
+	ll          *LogLevels
+	enable      os.Signal
+	disable     os.Signal
+	signals     chan os.Signal
+	lock        sync.Mutex
+	enabled     bool
+	previous    zapcore.Level
+	hadPrevious bool
+	stop        chan struct{}
+	stopOnce    sync.Once
+	done        chan struct{}
+}
+
+// NotifyDebugSignals installs a signal handler on the LogLevels, when the enable signal is
+// received every logger logs at debug, when the disable signal is received the loggers go
+// back to their levels from before debug was enabled.
+//
+// Debug is enabled with a level override (see SetLevelOverride) so the named levels and
+// rules are not changed or saved to a level store, any override set before debug was
+// enabled is put back when it is disabled.
+//
+// If enable and disable are the same signal, it toggles between the two.
+func NotifyDebugSignals(ll *LogLevels, enable, disable os.Signal) *DebugSignalHandler {
+	h := &DebugSignalHandler{
+		ll:      ll,
+		enable:  enable,
+		disable: disable,
+		signals: make(chan os.Signal, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	signal.Notify(h.signals, enable, disable)
+
+	go h.run()
+
+	return h
+}
+
+// run handles signals until the handler is stopped.
+func (h *DebugSignalHandler) run() {
+	defer close(h.done)
+
+	for {
+		select {
+		case <-h.stop:
+			return
+		case sig := <-h.signals:
+			h.handle(sig)
+		}
+	}
+}
+
+// handle enables or restores debug for the signal.
+func (h *DebugSignalHandler) handle(sig os.Signal) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	switch {
+	case sig == h.enable && !h.enabled:
+		h.enableDebug(sig)
+	case sig == h.disable && h.enabled:
+		h.restore(sig)
+	default:
+		h.ll.iLogger.Info("ignoring debug signal, already in requested state", zap.Stringer("signal", sig))
+	}
+}
+
+// enableDebug stores the current level override and overrides every level with debug.
+//
+// Callers must hold the lock.
+func (h *DebugSignalHandler) enableDebug(sig os.Signal) {
+	h.previous, h.hadPrevious = h.ll.LevelOverride()
+	h.enabled = true
+
+	h.ll.SetLevelOverrideBy(zapcore.DebugLevel, ChangeSource{
+		Origin: "signal",
+		Reason: "debug signal " + sig.String() + " received",
+	})
+	h.ll.iLogger.Info("debug signal received, all levels set to debug", zap.Stringer("signal", sig))
+}
+
+// restore restores the level override from when debug was enabled.
+//
+// Callers must hold the lock.
+func (h *DebugSignalHandler) restore(sig os.Signal) {
+	src := ChangeSource{Origin: "signal"}
+
+	if sig != nil {
+		h.ll.iLogger.Info("debug signal received, restoring previous levels", zap.Stringer("signal", sig))
+		src.Reason = "debug signal " + sig.String() + " received"
+	} else {
+		h.ll.iLogger.Info("debug signal handler stopped, restoring previous levels")
+		src.Reason = "debug signal handler stopped"
+	}
+
+	if h.hadPrevious {
+		h.ll.SetLevelOverrideBy(h.previous, src)
+	} else {
+		h.ll.ClearLevelOverrideBy(src)
+	}
+
+	h.enabled = false
+}
+
+// Enabled returns true if debug has been enabled by a signal and not yet restored.
+func (h *DebugSignalHandler) Enabled() bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return h.enabled
+}
+
+// Stop removes the signal handler, if debug is enabled the previous level override is restored.
+func (h *DebugSignalHandler) Stop() {
+	h.stopOnce.Do(func() {
+		signal.Stop(h.signals)
+		close(h.stop)
+	})
+
+	<-h.done
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.enabled {
+		h.restore(nil)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build unix
+
+package zaptool
+
+import "syscall"
+
+// NotifyDebugSignalsUSR installs a signal handler on the LogLevels that sets every level to
+// debug on SIGUSR1 and restores the previous levels on SIGUSR2.
+func NotifyDebugSignalsUSR(ll *LogLevels) *DebugSignalHandler {
+	return NotifyDebugSignals(ll, syscall.SIGUSR1, syscall.SIGUSR2)
+}
@@ -0,0 +1,120 @@
+//go:build unix
+
+package zaptool_test
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// waitForDebugSignal waits for the handler to reach the enabled state.
+func waitForDebugSignal(t *testing.T, handler *zaptool.DebugSignalHandler, enabled bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) && handler.Enabled() != enabled {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if handler.Enabled() != enabled {
+		t.Fatalf("debug enabled: got %t, want %t", handler.Enabled(), enabled)
+	}
+}
+
+func TestNotifyDebugSignalsUSR(t *testing.T) {
+	fac, observedLogs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(fac)
+
+	store := zaptool.NewFileStore(filepath.Join(t.TempDir(), "levels.json"))
+
+	loglvls := zaptool.NewLogLevels(logger, zapcore.InfoLevel, zaptool.LogLevelsStore(store))
+	process := loglvls.Named("Server.Process", zapcore.WarnLevel)
+	loglvls.Named("Client")
+
+	before := loglvls.String()
+
+	handler := zaptool.NotifyDebugSignalsUSR(loglvls)
+	defer handler.Stop()
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatalf("syscall.Kill returned error: %s", err)
+	}
+
+	waitForDebugSignal(t, handler, true)
+
+	process.Debug("debug enabled")
+
+	if observedLogs.FilterMessage("debug enabled").Len() != 1 {
+		t.Error("loggers should log at debug while debug is enabled")
+	}
+
+	if loglvls.String() != before || len(loglvls.Rules()) != 0 {
+		t.Errorf("named levels and rules should not change: '%s' %v", loglvls.String(), loglvls.Rules())
+	}
+
+	if _, err := store.Load(); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("enabling debug should not save to the level store: %v", err)
+	}
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR2); err != nil {
+		t.Fatalf("syscall.Kill returned error: %s", err)
+	}
+
+	waitForDebugSignal(t, handler, false)
+
+	process.Debug("debug disabled")
+	process.Info("debug disabled")
+
+	if observedLogs.FilterMessage("debug disabled").Len() != 0 {
+		t.Error("loggers should go back to their named levels")
+	}
+
+	if _, ok := loglvls.LevelOverride(); ok {
+		t.Error("the level override should be cleared")
+	}
+
+	if observedLogs.FilterLoggerName("Internal.LogLevels").FilterMessageSnippet("debug signal received").Len() != 2 {
+		t.Errorf("transitions should be logged to the Internal.LogLevels logger")
+	}
+}
+
+func TestDebugSignalHandler_StopRestores(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.Named("Server.Process")
+	loglvls.SetLevelOverride(zapcore.ErrorLevel)
+
+	handler := zaptool.NotifyDebugSignals(loglvls, syscall.SIGUSR1, syscall.SIGUSR1)
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatalf("syscall.Kill returned error: %s", err)
+	}
+
+	waitForDebugSignal(t, handler, true)
+
+	if lvl, ok := loglvls.LevelOverride(); !ok || lvl != zapcore.DebugLevel {
+		t.Errorf("debug should be set as the level override: %s", lvl)
+	}
+
+	handler.Stop()
+	handler.Stop()
+
+	if lvl, ok := loglvls.LevelOverride(); !ok || lvl != zapcore.ErrorLevel {
+		t.Errorf("Stop should restore the previous level override: %s, %t", lvl, ok)
+	}
+
+	if loglvls.String() != "Internal.LogLevels:info,Server.Process:info" {
+		t.Errorf("named levels should not change: %s", loglvls.String())
+	}
+}
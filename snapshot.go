@@ -0,0 +1,132 @@
+package zaptool
+
+import (
+	"sort"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LevelSnapshot is the state of a LogLevels captured by Snapshot, it can be
+// serialised as JSON and applied with Restore.
+type LevelSnapshot struct {
+	Levels   []SnapshotLevel `json:"levels"`
+	Rules    []LevelRule     `json:"rules"`
+	Sampling []SamplingRule  `json:"sampling,omitempty"`
+}
+
+// SnapshotLevel is a named level in a LevelSnapshot.
+type SnapshotLevel struct {
+	Name     string        `json:"name"`
+	Level    zapcore.Level `json:"level"`
+	Explicit bool          `json:"explicit,omitempty"`
+}
+
+// Snapshot returns the configured level of every name, the stored rules and the sampling
+// policies, active elevations are not included.
+func (a *LogLevels) Snapshot() LevelSnapshot {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	return a.snapshot()
+}
+
+// snapshot returns the current LevelSnapshot.
+//
+// Callers must hold the lock.
+func (a *LogLevels) snapshot() LevelSnapshot {
+	out := LevelSnapshot{
+		Levels: make([]SnapshotLevel, 0, len(a.levels)),
+		Rules:  make([]LevelRule, len(a.rules)),
+	}
+
+	copy(out.Rules, a.rules)
+
+	if len(a.sampling) > 0 {
+		out.Sampling = make([]SamplingRule, len(a.sampling))
+		copy(out.Sampling, a.sampling)
+	}
+
+	for k, v := range a.levels {
+		out.Levels = append(out.Levels, SnapshotLevel{Name: k, Level: v.configured, Explicit: v.explicit})
+	}
+
+	sort.Slice(out.Levels, func(i, j int) bool {
+		return out.Levels[i].Name < out.Levels[j].Name
+	})
+
+	return out
+}
+
+// Restore replaces the stored rules and levels with those in the snapshot.
+//
+// Names in the snapshot that do not exist are created so loggers created later
+// start at the restored level. Existing names that are not in the snapshot are
+// not deleted (loggers may still reference them), they are reset as if they had
+// just been created.
+func (a *LogLevels) Restore(snapshot LevelSnapshot) {
+	a.RestoreBy(snapshot, ChangeSource{})
+}
+
+// RestoreBy is Restore with the source of the change recorded in the history (and
+// audit logger if there is one).
+func (a *LogLevels) RestoreBy(snapshot LevelSnapshot, src ChangeSource) {
+	a.iLogger.Debug("Restore", zap.Int("levels", len(snapshot.Levels)), zap.Int("rules", len(snapshot.Rules)))
+
+	a.lock.Lock()
+	a.restore(snapshot)
+	pending := a.persist()
+	a.lock.Unlock()
+
+	pending.save()
+
+	a.record(LevelChange{
+		Action:  LevelChangeRestore,
+		Matched: len(snapshot.Levels) > 0,
+		Source:  src,
+	})
+}
+
+// restore replaces the stored rules and levels with those in the snapshot.
+//
+// Callers must hold the lock.
+func (a *LogLevels) restore(snapshot LevelSnapshot) {
+	a.rules = a.normaliseRules(snapshot.Rules)
+	a.sampling = a.sampling[:0]
+
+	for _, rule := range snapshot.Sampling {
+		a.sampling = append(a.sampling, SamplingRule{Pattern: a.normaliseName(rule.Pattern), Policy: rule.Policy})
+	}
+
+	restored := make(map[string]bool, len(snapshot.Levels))
+
+	for _, item := range snapshot.Levels {
+		item.Name = a.normaliseName(item.Name)
+		restored[item.Name] = true
+
+		entry, ok := a.levels[item.Name]
+		if !ok {
+			atom := zap.NewAtomicLevelAt(a.effectiveLevel(item.Name, item.Level, item.Explicit))
+			entry = newLevelEntry(item.Level)
+			entry.level = &atom
+			entry.explicit = item.Explicit
+			a.addEntry(item.Name, entry)
+
+			continue
+		}
+
+		entry.explicit = item.Explicit
+		a.setEntryLevel(item.Name, entry, item.Level, item.Name)
+	}
+
+	for k, v := range a.levels {
+		if restored[k] {
+			continue
+		}
+
+		a.resetEntry(k, v, k)
+	}
+
+	a.inherit("")
+	a.refreshSampling("")
+}
@@ -0,0 +1,94 @@
+package zaptool_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogLevels_SnapshotRestore(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.Named("Server")
+	loglvls.Named("Server.Process")
+	loglvls.SetLevel("Client.*", zapcore.WarnLevel)
+	loglvls.SetLevel("Server.Process", zapcore.ErrorLevel)
+
+	before := loglvls.String()
+	snapshot := loglvls.Snapshot()
+
+	loglvls.SetLevel("*", zapcore.DebugLevel)
+	loglvls.Named("Client.Conn")
+	loglvls.Named("Other")
+
+	// Client.Conn is created after "*" is set, but "Client.*" is the more specific rule.
+	if loglvls.String() != "Client.Conn:warn,Internal.LogLevels:debug,Other:debug,Server.Process:debug,Server:debug" {
+		t.Errorf("levels: got '%s'", loglvls.String())
+	}
+
+	loglvls.Restore(snapshot)
+
+	expect := "Client.Conn:warn,Internal.LogLevels:info,Other:info,Server.Process:error,Server:info"
+	if loglvls.String() != expect {
+		t.Errorf("levels: got '%s', want '%s'", loglvls.String(), expect)
+	}
+
+	loglvls.DeleteLevel("Client.Conn")
+	loglvls.DeleteLevel("Other")
+
+	if loglvls.String() != before {
+		t.Errorf("levels: got '%s', want '%s'", loglvls.String(), before)
+	}
+
+	loglvls.SetLevel("Server", zapcore.DebugLevel)
+
+	if loglvls.String() != "Internal.LogLevels:info,Server.Process:error,Server:debug" {
+		t.Errorf("restored explicit levels should not inherit: %s", loglvls.String())
+	}
+}
+
+func TestLogLevels_SnapshotJSON(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	source := zaptool.NewLogLevels(logger)
+	source.Named("Server.Process")
+	source.SetLevel("Server.*", zapcore.DebugLevel)
+
+	data, err := json.Marshal(source.Snapshot())
+	if err != nil {
+		t.Fatalf("json.Marshal returned error: %s", err)
+	}
+
+	expectJSON := `{"levels":[{"name":"Internal.LogLevels","level":"info"},` +
+		`{"name":"Server.Process","level":"debug","explicit":true}],` +
+		`"rules":[{"pattern":"Server.*","level":"debug"}]}`
+
+	if string(data) != expectJSON {
+		t.Errorf("json: got '%s', want '%s'", data, expectJSON)
+	}
+
+	var snapshot zaptool.LevelSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatalf("json.Unmarshal returned error: %s", err)
+	}
+
+	target := zaptool.NewLogLevels(logger)
+	target.Restore(snapshot)
+
+	if target.String() != source.String() {
+		t.Errorf("levels: got '%s', want '%s'", target.String(), source.String())
+	}
+
+	target.Named("Server.Other")
+
+	if target.String() != "Internal.LogLevels:info,Server.Other:debug,Server.Process:debug" {
+		t.Errorf("restored rules should apply to later loggers: %s", target.String())
+	}
+}
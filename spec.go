@@ -0,0 +1,167 @@
+package zaptool
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// ErrInvalidSpecEntry is returned when an entry in a level spec is not in the form "name=level".
+var ErrInvalidSpecEntry = errors.New("invalid spec entry")
+
+// SpecEntryError is an error for a single entry in a level spec.
+type SpecEntryError struct {
+	Entry string
+	Err   error
+}
+
+func (e SpecEntryError) Error() string {
+	return fmt.Sprintf("%q: %s", e.Entry, e.Err.Error())
+}
+
+func (e SpecEntryError) Unwrap() error {
+	return e.Err
+}
+
+// SpecError is returned when a level spec contains invalid entries, it lists
+// every invalid entry.
+type SpecError struct {
+	Entries []SpecEntryError
+}
+
+func (e *SpecError) Error() string {
+	out := make([]string, 0, len(e.Entries))
+	for _, entry := range e.Entries {
+		out = append(out, entry.Error())
+	}
+
+	return "invalid level spec: " + strings.Join(out, ", ")
+}
+
+// Unwrap returns the errors for each invalid entry.
+func (e *SpecError) Unwrap() []error {
+	out := make([]error, 0, len(e.Entries))
+	for _, entry := range e.Entries {
+		out = append(out, entry)
+	}
+
+	return out
+}
+
+// ParseLevelSpec parses a comma separated list of "name=level" entries, the name can be a
+// wildcard pattern and ":" is accepted in place of "=" so the output of LogLevels.String
+// can be parsed.
+//
+// The default entry ("*=level") is returned first, other entries are returned in order.
+func ParseLevelSpec(spec string) ([]LevelRule, error) {
+	defaults := []LevelRule{}
+	out := []LevelRule{}
+	specErr := &SpecError{}
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		idx := strings.LastIndexAny(entry, "=:")
+		if idx <= 0 {
+			specErr.Entries = append(specErr.Entries, SpecEntryError{Entry: entry, Err: ErrInvalidSpecEntry})
+			continue
+		}
+
+		name := strings.TrimSpace(entry[:idx])
+		lvl := strings.TrimSpace(entry[idx+1:])
+
+		if err := ValidatePattern(name); err != nil {
+			specErr.Entries = append(specErr.Entries, SpecEntryError{Entry: entry, Err: err})
+			continue
+		}
+
+		level, ok := parseLevel(lvl)
+		if !ok {
+			specErr.Entries = append(specErr.Entries, SpecEntryError{
+				Entry: entry,
+				Err:   fmt.Errorf("%w: %q", ErrUnknownLevel, lvl),
+			})
+
+			continue
+		}
+
+		if name == "*" {
+			defaults = append(defaults, LevelRule{Pattern: name, Level: level})
+			continue
+		}
+
+		out = append(out, LevelRule{Pattern: name, Level: level})
+	}
+
+	if len(specErr.Entries) > 0 {
+		return nil, specErr
+	}
+
+	return append(defaults, out...), nil
+}
+
+// ApplySpec parses the level spec (see ParseLevelSpec) and sets each level, if any entries
+// are invalid a *SpecError is returned and no levels are changed.
+func (a *LogLevels) ApplySpec(spec string) error {
+	return a.ApplySpecBy(spec, ChangeSource{})
+}
+
+// ApplySpecBy is ApplySpec with the source of the change recorded in the history (and audit
+// logger if there is one), each level set by the spec is recorded as a separate change.
+func (a *LogLevels) ApplySpecBy(spec string, src ChangeSource) error {
+	a.iLogger.Debug("ApplySpec", zap.String("spec", spec))
+
+	rules, err := ParseLevelSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	a.applyRulesBy(nil, rules, src)
+
+	return nil
+}
+
+// applyRulesBy clears the patterns and sets the rules as a single change, so the store is
+// saved once for all of them, each pattern cleared and level set is recorded as a separate
+// change.
+func (a *LogLevels) applyRulesBy(cleared []string, rules []LevelRule, src ChangeSource) {
+	rules = a.normaliseRules(rules)
+	changes := make([]LevelChange, 0, len(cleared)+len(rules))
+
+	a.lock.Lock()
+
+	for _, pattern := range cleared {
+		pattern = a.normaliseName(pattern)
+
+		changes = append(changes, LevelChange{
+			Action:  LevelChangeClear,
+			Pattern: pattern,
+			Matched: a.clearLevel(pattern),
+			Source:  src,
+		})
+	}
+
+	for _, rule := range rules {
+		changes = append(changes, LevelChange{
+			Action:  LevelChangeSet,
+			Pattern: rule.Pattern,
+			Level:   rule.Level.String(),
+			Matched: a.setLevel(rule.Pattern, rule.Level),
+			Source:  src,
+		})
+	}
+
+	pending := a.persist()
+	a.lock.Unlock()
+
+	pending.save()
+
+	for _, change := range changes {
+		a.record(change)
+	}
+}
@@ -0,0 +1,90 @@
+package zaptool_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogLevels_ApplySpec(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.Named("Server.Process")
+	loglvls.Named("Client")
+
+	if err := loglvls.ApplySpec("Server.*=debug, Internal.*=warn,*=error"); err != nil {
+		t.Fatalf("ApplySpec returned error: %s", err)
+	}
+
+	expect := "Client:error,Internal.LogLevels:warn,Server.Process:debug"
+	if loglvls.String() != expect {
+		t.Errorf("levels: got '%s', want '%s'", loglvls.String(), expect)
+	}
+
+	loglvls.Named("Other")
+
+	if !loglvls.IsLogger("Other") || loglvls.String() != "Client:error,Internal.LogLevels:warn,Other:error,Server.Process:debug" {
+		t.Errorf("default level should apply to later loggers: %s", loglvls.String())
+	}
+}
+
+func TestLogLevels_ApplySpecRoundTrip(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	source := zaptool.NewLogLevels(logger)
+	source.Named("Server.Process", zapcore.DebugLevel)
+	source.Named("Client", zapcore.ErrorLevel)
+
+	target := zaptool.NewLogLevels(logger)
+	if err := target.ApplySpec(source.String()); err != nil {
+		t.Fatalf("ApplySpec returned error: %s", err)
+	}
+
+	target.Named("Server.Process")
+	target.Named("Client")
+
+	if target.String() != source.String() {
+		t.Errorf("levels: got '%s', want '%s'", target.String(), source.String())
+	}
+}
+
+func TestLogLevels_ApplySpecInvalid(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.Named("Server.Process")
+
+	err := loglvls.ApplySpec("Server.*=debug,Client=loud,bogus,=info")
+	if err == nil {
+		t.Fatal("ApplySpec should return an error")
+	}
+
+	var specErr *zaptool.SpecError
+	if !errors.As(err, &specErr) {
+		t.Fatalf("error should be a *SpecError: %T", err)
+	}
+
+	if len(specErr.Entries) != 3 {
+		t.Errorf("SpecError should list 3 invalid entries, got %d: %s", len(specErr.Entries), err)
+	}
+
+	if !errors.Is(err, zaptool.ErrUnknownLevel) || !errors.Is(err, zaptool.ErrInvalidSpecEntry) {
+		t.Errorf("SpecError should wrap the entry errors: %s", err)
+	}
+
+	if loglvls.String() != "Internal.LogLevels:info,Server.Process:info" {
+		t.Errorf("no levels should change when the spec is invalid: %s", loglvls.String())
+	}
+
+	if len(loglvls.Rules()) != 0 {
+		t.Errorf("no rules should be stored when the spec is invalid: %v", loglvls.Rules())
+	}
+}
@@ -0,0 +1,176 @@
+package zaptool
+
+import (
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// statsLevelCount is the number of levels counted, from debug to fatal.
+const statsLevelCount = int(zapcore.FatalLevel-zapcore.DebugLevel) + 1
+
+// LoggerStats is the number of entries written and dropped by a named logger.
+type LoggerStats struct {
+	Name string `json:"name"`
+	// Written is the number of entries written by level, levels without entries are omitted.
+	Written map[zapcore.Level]uint64 `json:"written"`
+	// Dropped is the number of entries dropped because they were below the level, levels
+	// without entries are omitted.
+	Dropped map[zapcore.Level]uint64 `json:"dropped"`
+	// Sampled is the number of entries dropped by the sampling policy, levels without
+	// entries are omitted.
+	Sampled map[zapcore.Level]uint64 `json:"sampled"`
+	// Created is the time the level was created.
+	Created time.Time `json:"created"`
+	// LastWrite is the time of the last entry written, it is zero if nothing has been written
+	// (and is encoded as the zero time in JSON).
+	LastWrite time.Time `json:"lastWrite"`
+}
+
+// TotalWritten returns the number of entries written at any level.
+func (s LoggerStats) TotalWritten() uint64 {
+	return sumCounts(s.Written)
+}
+
+// TotalDropped returns the number of entries dropped at any level.
+func (s LoggerStats) TotalDropped() uint64 {
+	return sumCounts(s.Dropped)
+}
+
+// TotalSampled returns the number of entries dropped by the sampling policy at any level.
+func (s LoggerStats) TotalSampled() uint64 {
+	return sumCounts(s.Sampled)
+}
+
+// sumCounts returns the sum of the counts for all levels.
+func sumCounts(counts map[zapcore.Level]uint64) uint64 {
+	var out uint64
+
+	for _, v := range counts {
+		out += v
+	}
+
+	return out
+}
+
+// loggerStats holds the counters for a named level, it is shared by every logger
+// created for the name.
+type loggerStats struct {
+	created   time.Time
+	lastWrite atomic.Int64
+	written   [statsLevelCount]atomic.Uint64
+	dropped   [statsLevelCount]atomic.Uint64
+	sampled   [statsLevelCount]atomic.Uint64
+}
+
+// newLoggerStats returns empty counters for a level created now.
+func newLoggerStats() *loggerStats {
+	return &loggerStats{
+		created: time.Now(),
+	}
+}
+
+// statsIndex returns the counter index for the level, levels outside debug to fatal
+// are not counted.
+func statsIndex(lvl zapcore.Level) (int, bool) {
+	if lvl < zapcore.DebugLevel || lvl > zapcore.FatalLevel {
+		return 0, false
+	}
+
+	return int(lvl - zapcore.DebugLevel), true
+}
+
+// write counts an entry written and records the time of the entry.
+func (s *loggerStats) write(ent zapcore.Entry) {
+	if idx, ok := statsIndex(ent.Level); ok {
+		s.written[idx].Add(1)
+	}
+
+	t := ent.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	s.lastWrite.Store(t.UnixNano())
+}
+
+// drop counts an entry dropped at the level.
+func (s *loggerStats) drop(lvl zapcore.Level) {
+	if idx, ok := statsIndex(lvl); ok {
+		s.dropped[idx].Add(1)
+	}
+}
+
+// sample counts an entry dropped by the sampling policy at the level.
+func (s *loggerStats) sample(lvl zapcore.Level) {
+	if idx, ok := statsIndex(lvl); ok {
+		s.sampled[idx].Add(1)
+	}
+}
+
+// stats returns a copy of the current counters.
+func (s *loggerStats) stats(name string) LoggerStats {
+	out := LoggerStats{
+		Name:    name,
+		Written: map[zapcore.Level]uint64{},
+		Dropped: map[zapcore.Level]uint64{},
+		Sampled: map[zapcore.Level]uint64{},
+		Created: s.created,
+	}
+
+	for idx := 0; idx < statsLevelCount; idx++ {
+		lvl := zapcore.DebugLevel + zapcore.Level(idx)
+
+		if v := s.written[idx].Load(); v > 0 {
+			out.Written[lvl] = v
+		}
+
+		if v := s.dropped[idx].Load(); v > 0 {
+			out.Dropped[lvl] = v
+		}
+
+		if v := s.sampled[idx].Load(); v > 0 {
+			out.Sampled[lvl] = v
+		}
+	}
+
+	if v := s.lastWrite.Load(); v != 0 {
+		out.LastWrite = time.Unix(0, v)
+	}
+
+	return out
+}
+
+// Stats returns the entry counters for the named logger, it returns false if there
+// is no level with the name. Names are matched regardless of case as they are by SetLevel.
+//
+// Entries are only counted for loggers created by Named.
+func (a *LogLevels) Stats(name string) (LoggerStats, bool) {
+	name = a.normaliseName(name)
+
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	key, v, ok := a.lookupLevel(name)
+	if !ok {
+		return LoggerStats{}, false
+	}
+
+	return v.stats.stats(key), true
+}
+
+// StatsIterator runs a callback function over the entry counters of every named
+// level item by item.
+func (a *LogLevels) StatsIterator(f func(LoggerStats) error) error {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	for k, v := range a.levels {
+		if err := f(v.stats.stats(k)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package zaptool_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogLevels_Stats(t *testing.T) {
+	fac, observedLogs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger, zapcore.InfoLevel)
+	named := loglvls.Named("Server.Process", zapcore.InfoLevel)
+	loglvls.Named("Server.Unused")
+
+	start := time.Now()
+
+	named.Debug("dropped")
+	named.Debug("dropped")
+	named.Info("written")
+	named.With(zap.String("request_id", "abc")).Warn("written")
+	named.Named("Child").Error("written")
+
+	if ce := named.Check(zapcore.DebugLevel, "dropped"); ce != nil {
+		t.Error("Check should not return an entry below the level")
+	}
+
+	_ = named.Level()
+
+	if logs := observedLogs.FilterLoggerName("Server.Process").Len() +
+		observedLogs.FilterLoggerName("Server.Process.Child").Len(); logs != 3 {
+		t.Errorf("should contain 3 log messages, instead contained %d messages", logs)
+	}
+
+	stats, ok := loglvls.Stats("Server.Process")
+	if !ok {
+		t.Fatal("Stats should return true for an existing level")
+	}
+
+	if stats.Name != "Server.Process" {
+		t.Errorf("Name: got '%s'", stats.Name)
+	}
+
+	if stats.TotalWritten() != 3 || stats.Written[zapcore.InfoLevel] != 1 ||
+		stats.Written[zapcore.WarnLevel] != 1 || stats.Written[zapcore.ErrorLevel] != 1 {
+		t.Errorf("Written: got %v", stats.Written)
+	}
+
+	if stats.TotalDropped() != 3 || stats.Dropped[zapcore.DebugLevel] != 3 {
+		t.Errorf("Dropped: got %v", stats.Dropped)
+	}
+
+	if stats.LastWrite.Before(start) {
+		t.Errorf("LastWrite should be after the start of the test: %s", stats.LastWrite)
+	}
+
+	if stats.Created.After(start) {
+		t.Errorf("Created should be before the start of the test: %s", stats.Created)
+	}
+
+	unusedStats, ok := loglvls.Stats("Server.Unused")
+	if !ok {
+		t.Fatal("Stats should return true for an existing level")
+	}
+
+	if !unusedStats.LastWrite.IsZero() || unusedStats.TotalWritten() != 0 || unusedStats.TotalDropped() != 0 {
+		t.Errorf("unused logger should have no entries: %+v", unusedStats)
+	}
+
+	data, err := json.Marshal(unusedStats)
+	if err != nil {
+		t.Fatalf("json.Marshal returned error: %s", err)
+	}
+
+	if !strings.Contains(string(data), `"lastWrite":"0001-01-01T00:00:00Z"`) {
+		t.Errorf("unused logger should encode the zero last write: %s", data)
+	}
+
+	if _, ok := loglvls.Stats("Server.Missing"); ok {
+		t.Error("Stats should return false for a missing level")
+	}
+}
+
+func TestLogLevels_StatsFloor(t *testing.T) {
+	fac, _ := observer.New(zapcore.DebugLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger, zapcore.InfoLevel)
+	named := loglvls.Named("Server")
+
+	loglvls.SetLevelFloor(zapcore.WarnLevel)
+
+	named.Info("dropped")
+	named.Warn("written")
+
+	stats, _ := loglvls.Stats("Server")
+	if stats.Dropped[zapcore.InfoLevel] != 1 || stats.Written[zapcore.WarnLevel] != 1 {
+		t.Errorf("entries below the floor should be dropped: %+v", stats)
+	}
+}
+
+func TestLogLevels_StatsIterator(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.Named("Server").Info("written")
+	loglvls.Named("Client")
+
+	written := map[string]uint64{}
+
+	if err := loglvls.StatsIterator(func(stats zaptool.LoggerStats) error {
+		written[stats.Name] = stats.TotalWritten()
+		return nil
+	}); err != nil {
+		t.Errorf("StatsIterator returned error: %s", err)
+	}
+
+	if len(written) != 3 || written["Server"] != 1 || written["Client"] != 0 {
+		t.Errorf("StatsIterator: got %v", written)
+	}
+}
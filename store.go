@@ -0,0 +1,161 @@
+package zaptool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+)
+
+// LevelStore persists the state of a LogLevels between restarts.
+type LevelStore interface {
+	// Load returns the stored snapshot, the error wraps fs.ErrNotExist if nothing
+	// has been stored yet.
+	Load() (LevelSnapshot, error)
+	// Save stores the snapshot.
+	Save(snapshot LevelSnapshot) error
+}
+
+// FileStore is a LevelStore that keeps the snapshot as JSON in a local file.
+type FileStore struct {
+	path string
+}
+
+// NewFileStore returns a FileStore that stores the snapshot in the file at path.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{
+		path: path,
+	}
+}
+
+// Load reads the snapshot from the file.
+func (s *FileStore) Load() (LevelSnapshot, error) {
+	var out LevelSnapshot
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return out, fmt.Errorf("unable to read level store: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &out); err != nil {
+		return LevelSnapshot{}, fmt.Errorf("unable to decode level store %s: %w", s.path, err)
+	}
+
+	return out, nil
+}
+
+// Save writes the snapshot to a temporary file in the same directory and renames it over
+// the file so a partially written file is never left in place.
+func (s *FileStore) Save(snapshot LevelSnapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode level store: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), "."+filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("unable to create temporary level store: %w", err)
+	}
+
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("unable to write level store: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("unable to sync level store: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to close level store: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("unable to replace level store: %w", err)
+	}
+
+	return nil
+}
+
+// LogLevelsStore loads the state stored in the LevelStore and saves the state back to it
+// whenever levels or rules are changed.
+//
+// If the stored state can not be loaded the error is logged to the Internal.LogLevels
+// logger and the default levels are kept. Save is called after the change is applied (so
+// it does not block the loggers), concurrent changes may be saved in one call.
+func LogLevelsStore(store LevelStore) func(*LogLevels) {
+	return func(ll *LogLevels) {
+		snapshot, err := store.Load()
+
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			ll.iLogger.Warn("unable to load level store, using default levels", zap.Error(err))
+		default:
+			ll.RestoreBy(snapshot, ChangeSource{Origin: "store", Reason: "loaded from level store"})
+		}
+
+		ll.lock.Lock()
+		defer ll.lock.Unlock()
+
+		ll.store = store
+	}
+}
+
+// pendingSave is a snapshot of the state waiting to be saved to the store, it is saved
+// after the lock is released so a slow store does not block the loggers.
+type pendingSave struct {
+	ll       *LogLevels
+	store    LevelStore
+	snapshot LevelSnapshot
+	seq      uint64
+}
+
+// persist takes a snapshot of the current state to save to the store if one is configured,
+// the returned pendingSave must be saved once the lock is released.
+//
+// Callers must hold the lock.
+func (a *LogLevels) persist() pendingSave {
+	if a.store == nil {
+		return pendingSave{}
+	}
+
+	a.saveSeq++
+
+	return pendingSave{
+		ll:       a,
+		store:    a.store,
+		snapshot: a.snapshot(),
+		seq:      a.saveSeq,
+	}
+}
+
+// save saves the snapshot to the store, unless a snapshot taken after it has already been
+// saved (so concurrent changes can not replace a newer state with an older one).
+//
+// Callers must not hold the lock.
+func (s pendingSave) save() {
+	if s.store == nil {
+		return
+	}
+
+	s.ll.saveLock.Lock()
+	defer s.ll.saveLock.Unlock()
+
+	if s.seq <= s.ll.savedSeq {
+		return
+	}
+
+	s.ll.savedSeq = s.seq
+
+	if err := s.store.Save(s.snapshot); err != nil {
+		s.ll.iLogger.Warn("unable to save level store", zap.Error(err))
+	}
+}
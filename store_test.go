@@ -0,0 +1,154 @@
+package zaptool_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestFileStore_PersistAndReload(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	path := filepath.Join(t.TempDir(), "levels.json")
+
+	first := zaptool.NewLogLevels(logger, zaptool.LogLevelsStore(zaptool.NewFileStore(path)))
+	first.Named("Server.Process")
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("store should not be written until levels change: %v", err)
+	}
+
+	first.SetLevel("Server.*", zapcore.DebugLevel)
+	first.SetLevel("Client", zapcore.WarnLevel)
+
+	second := zaptool.NewLogLevels(logger, zaptool.LogLevelsStore(zaptool.NewFileStore(path)))
+	second.Named("Server.Process")
+	second.Named("Client")
+
+	expect := "Client:warn,Internal.LogLevels:info,Server.Process:debug"
+	if second.String() != expect {
+		t.Errorf("levels: got '%s', want '%s'", second.String(), expect)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("os.ReadDir returned error: %s", err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("temporary files should be removed, found %d files", len(entries))
+	}
+}
+
+func TestFileStore_CorruptFile(t *testing.T) {
+	fac, observedLogs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	path := filepath.Join(t.TempDir(), "levels.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile returned error: %s", err)
+	}
+
+	loglvls := zaptool.NewLogLevels(logger, zaptool.LogLevelsStore(zaptool.NewFileStore(path)))
+	loglvls.Named("Server.Process")
+
+	if loglvls.String() != "Internal.LogLevels:info,Server.Process:info" {
+		t.Errorf("levels: got '%s'", loglvls.String())
+	}
+
+	warnings := observedLogs.FilterLoggerName("Internal.LogLevels").FilterLevelExact(zapcore.WarnLevel).All()
+	if len(warnings) != 1 {
+		t.Fatalf("should log 1 warning for the corrupt store, logged %d", len(warnings))
+	}
+
+	loglvls.SetLevel("Server.Process", zapcore.ErrorLevel)
+
+	if _, err := zaptool.NewFileStore(path).Load(); err != nil {
+		t.Errorf("corrupt store should be replaced on the next change: %s", err)
+	}
+}
+
+// readingStore is a LevelStore that reads the levels while saving.
+type readingStore struct {
+	*zaptool.MemoryStore
+
+	ll    *zaptool.LogLevels
+	saved []string
+}
+
+func (s *readingStore) Save(snapshot zaptool.LevelSnapshot) error {
+	if s.ll != nil {
+		s.saved = append(s.saved, s.ll.String())
+	}
+
+	return s.MemoryStore.Save(snapshot)
+}
+
+func TestLogLevelsStore_SaveWithoutLock(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	store := &readingStore{MemoryStore: zaptool.NewMemoryStore()}
+	loglvls := zaptool.NewLogLevels(logger, zaptool.LogLevelsStore(store))
+	loglvls.Named("Server.Process")
+
+	store.ll = loglvls
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		loglvls.SetLevel("Server.*", zapcore.DebugLevel)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("SetLevel should not hold the lock while saving to the store")
+	}
+
+	if len(store.saved) != 1 || store.saved[0] != "Internal.LogLevels:info,Server.Process:debug" {
+		t.Errorf("saved: got %v", store.saved)
+	}
+}
+
+func TestLogLevelsStore_ConcurrentSaves(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	store := zaptool.NewMemoryStore()
+	loglvls := zaptool.NewLogLevels(logger, zaptool.LogLevelsStore(store))
+
+	var wg sync.WaitGroup
+
+	for idx := 0; idx < 20; idx++ {
+		wg.Add(1)
+
+		go func(idx int) {
+			defer wg.Done()
+
+			loglvls.SetLevel(fmt.Sprintf("Server.%d", idx), zapcore.DebugLevel)
+		}(idx)
+	}
+
+	wg.Wait()
+
+	snapshot, err := store.Load()
+	if err != nil {
+		t.Fatalf("store.Load() returned error: %s", err)
+	}
+
+	if !reflect.DeepEqual(snapshot.Rules, loglvls.Rules()) {
+		t.Errorf("store should hold the latest rules: got %v, want %v", snapshot.Rules, loglvls.Rules())
+	}
+}
@@ -2,16 +2,30 @@ package zaptool
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
+	"strings"
 
 	"go.uber.org/zap"
 )
 
+// SubLogLevels is a view of a LogManager scoped to the names under a prefix, names and
+// patterns passed to it are relative to the prefix.
 type SubLogLevels struct {
 	prefix string
 	logmgr LogManager
 }
 
+// NewSubLogLevels returns a SubLogLevels scoped to the names under name, if logmgr is
+// itself a SubLogLevels the prefixes are joined.
 func NewSubLogLevels(name string, logmgr LogManager) *SubLogLevels {
+	if sub, ok := logmgr.(*SubLogLevels); ok {
+		return &SubLogLevels{
+			prefix: sub.levelName(name),
+			logmgr: sub.logmgr,
+		}
+	}
+
 	return &SubLogLevels{
 		prefix: name,
 		logmgr: logmgr,
@@ -22,6 +36,41 @@ func (s *SubLogLevels) levelName(name string) string {
 	return fmt.Sprintf("%s.%s", s.prefix, name)
 }
 
+// scopePrefix returns the prefix (including the trailing dot) that names in the parent
+// LogManager start with.
+func (s *SubLogLevels) scopePrefix() string {
+	if ll, ok := s.logmgr.(*LogLevels); ok {
+		return ll.normaliseName(s.prefix) + "."
+	}
+
+	return s.prefix + "."
+}
+
+// scopePattern returns the name or pattern resolved within the namespace of the prefix.
+func (s *SubLogLevels) scopePattern(pattern string) string {
+	m, err := compilePattern(pattern, false)
+	if err != nil {
+		return s.levelName(pattern)
+	}
+
+	quoted := regexp.QuoteMeta(s.prefix + ".")
+
+	switch m.kind {
+	case matcherExact, matcherGlob, matcherPrefix, matcherScoped:
+		return s.levelName(pattern)
+	case matcherAny:
+		return s.levelName("*")
+	case matcherSuffix, matcherContains:
+		// appended to the prefix these would become a segment glob, so they are scoped
+		// instead (which keeps their specificity).
+		return s.levelName("{" + pattern + "}")
+	case matcherRegexp:
+		return "/" + quoted + "(?:" + pattern[1:len(pattern)-1] + ")/"
+	}
+
+	return s.levelName(pattern)
+}
+
 func (s *SubLogLevels) NewLevel(name string) *zap.AtomicLevel {
 	return s.logmgr.NewLevel(s.levelName(name))
 }
@@ -30,22 +79,79 @@ func (s *SubLogLevels) Named(name string, opts ...interface{}) *zap.Logger {
 	return s.logmgr.Named(s.levelName(name), opts...)
 }
 
+// Iterator runs a callback function over the levels under the prefix, the names passed
+// to the callback have the prefix removed.
 func (s *SubLogLevels) Iterator(f func(string, *zap.AtomicLevel) error) error {
-	return s.logmgr.Iterator(f)
+	prefix := s.scopePrefix()
+
+	return s.logmgr.Iterator(func(name string, lvl *zap.AtomicLevel) error {
+		if !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+
+		return f(strings.TrimPrefix(name, prefix), lvl)
+	})
+}
+
+// hasMatch returns true if setting a level for the name (or pattern) within the prefix
+// would match any levels.
+func (s *SubLogLevels) hasMatch(pattern string) bool {
+	return hasMatch(s.logmgr, s.scopePattern(pattern))
 }
 
 func (s *SubLogLevels) IsLogger(name string) bool {
 	return s.logmgr.IsLogger(s.levelName(name))
 }
 
+// SetLevel sets the level for the name or wildcard pattern within the prefix.
 func (s *SubLogLevels) SetLevel(name string, lvl interface{}) bool {
-	return s.logmgr.SetLevel(s.levelName(name), lvl)
+	return s.logmgr.SetLevel(s.scopePattern(name), lvl)
+}
+
+// SetLevelBy is SetLevel with the source of the change recorded if the parent LogManager
+// records changes.
+func (s *SubLogLevels) SetLevelBy(name string, lvl interface{}, src ChangeSource) bool {
+	return setLevelBy(s.logmgr, s.scopePattern(name), lvl, src)
+}
+
+// ClearLevel removes the explicit level for the name if the parent LogManager
+// supports clearing levels, it returns false if it does not.
+func (s *SubLogLevels) ClearLevel(name string) bool {
+	if c, ok := s.logmgr.(interface{ ClearLevel(name string) bool }); ok {
+		return c.ClearLevel(s.scopePattern(name))
+	}
+
+	return false
 }
 
+// ClearLevelBy is ClearLevel with the source of the change recorded if the parent
+// LogManager records changes.
+func (s *SubLogLevels) ClearLevelBy(name string, src ChangeSource) bool {
+	return clearLevelBy(s.logmgr, s.scopePattern(name), src)
+}
+
+// DeleteLevel removes the levels matching the name or wildcard pattern within the prefix.
 func (s *SubLogLevels) DeleteLevel(name string) {
-	s.logmgr.DeleteLevel(s.levelName(name))
+	s.logmgr.DeleteLevel(s.scopePattern(name))
+}
+
+// DeleteLevelBy is DeleteLevel with the source of the change recorded if the parent
+// LogManager records changes.
+func (s *SubLogLevels) DeleteLevelBy(name string, src ChangeSource) {
+	deleteLevelBy(s.logmgr, s.scopePattern(name), src)
 }
 
+// String returns a string representation of the levels under the prefix, with the prefix
+// removed from the names.
 func (s *SubLogLevels) String() string {
-	return s.logmgr.String()
+	out := []string{}
+
+	_ = s.Iterator(func(name string, lvl *zap.AtomicLevel) error {
+		out = append(out, name+":"+levelString(lvl.Level()))
+		return nil
+	})
+
+	sort.Strings(out)
+
+	return strings.Join(out, ",")
 }
@@ -23,13 +23,13 @@ func TestSubLogLevels_Names(t *testing.T) {
 	sublvls.Named("Core").Debug("should not log")
 	sublvls.Named("Core").Info("should log")
 
-	if sublvls.String() != "Childlog.Core:info,Internal.LogLevels:info" {
+	if sublvls.String() != "Core:info" {
 		t.Errorf("Initial log level for Childlog.Core is not info: %s", sublvls.String())
 	}
 
 	sublvls.SetLevel("Core", zapcore.DebugLevel)
 
-	if sublvls.String() != "Childlog.Core:debug,Internal.LogLevels:info" {
+	if sublvls.String() != "Core:debug" {
 		t.Errorf("Updated log level for Childlog.Core is not debug: %s", sublvls.String())
 	}
 
@@ -51,3 +51,119 @@ func TestSubLogLevels_Names(t *testing.T) {
 		t.Errorf("should contain 3 log messages, instead contained %d messages", len(observedLogs.All()))
 	}
 }
+
+func TestSubLogLevels_Scoped(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.Named("Core")
+	loglvls.Named("Other.Worker")
+
+	sublvls := zaptool.NewSubLogLevels("Childlog", loglvls)
+	sublvls.Named("Core")
+	sublvls.Named("Core.Worker")
+	sublvls.Named("Queue.Worker")
+
+	if sublvls.String() != "Core.Worker:info,Core:info,Queue.Worker:info" {
+		t.Errorf("String should only include names under the prefix: %s", sublvls.String())
+	}
+
+	tests := []struct {
+		pattern string
+		expect  string
+	}{
+		{"*", "Core.Worker:debug,Core:debug,Queue.Worker:debug"},
+		{"*.Worker", "Core.Worker:debug,Core:info,Queue.Worker:debug"},
+		{"*Work*", "Core.Worker:debug,Core:info,Queue.Worker:debug"},
+		{"Core*", "Core.Worker:debug,Core:debug,Queue.Worker:info"},
+		{"**.Worker", "Core.Worker:debug,Core:info,Queue.Worker:debug"},
+		{"/(Core|Queue)\\.Worker/", "Core.Worker:debug,Core:info,Queue.Worker:debug"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			sublvls.SetLevel("*", zapcore.InfoLevel)
+
+			if !sublvls.SetLevel(tt.pattern, zapcore.DebugLevel) {
+				t.Error("SetLevel should return true when levels match")
+			}
+
+			if sublvls.String() != tt.expect {
+				t.Errorf("levels: got '%s', want '%s'", sublvls.String(), tt.expect)
+			}
+
+			if !strings.HasPrefix(loglvls.String(), "Childlog.") ||
+				!strings.HasSuffix(loglvls.String(), ",Core:info,Internal.LogLevels:info,Other.Worker:info") {
+				t.Errorf("levels outside the prefix should not change: %s", loglvls.String())
+			}
+		})
+	}
+
+	sublvls.DeleteLevel("*.Worker")
+
+	if sublvls.String() != "Core:info" {
+		t.Errorf("DeleteLevel should only delete names under the prefix: %s", sublvls.String())
+	}
+
+	if !loglvls.IsLogger("Other.Worker") {
+		t.Error("DeleteLevel should not delete names outside the prefix")
+	}
+}
+
+func TestSubLogLevels_ScopedSpecificity(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.SetLevel("Server.*", zapcore.WarnLevel)
+
+	sublvls := zaptool.NewSubLogLevels("Server", loglvls)
+	sublvls.SetLevel("*Worker", zapcore.DebugLevel)
+	sublvls.SetLevel("*Queue*", zapcore.ErrorLevel)
+
+	sublvls.Named("Worker")
+	sublvls.Named("Pool.Worker")
+	sublvls.Named("Queues")
+	sublvls.Named("Other")
+
+	// the scoped patterns are as specific as "Server.*Worker" and "Server.*Queue*", so they
+	// take precedence over "Server.*".
+	if sublvls.String() != "Other:warn,Pool.Worker:debug,Queues:error,Worker:debug" {
+		t.Errorf("levels: got '%s'", sublvls.String())
+	}
+}
+
+func TestSubLogLevels_Nested(t *testing.T) {
+	fac, observedLogs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	parent := zaptool.NewSubLogLevels("Server", loglvls)
+	child := zaptool.NewSubLogLevels("Process", parent)
+
+	child.Named("Worker").Info("should log")
+	parent.Named("Queue")
+
+	if logs := observedLogs.All(); len(logs) != 1 || logs[0].LoggerName != "Server.Process.Worker" {
+		t.Errorf("logger name should include both prefixes: %v", logs)
+	}
+
+	if child.String() != "Worker:info" {
+		t.Errorf("levels: got '%s'", child.String())
+	}
+
+	if parent.String() != "Process.Worker:info,Queue:info" {
+		t.Errorf("levels: got '%s'", parent.String())
+	}
+
+	child.SetLevel("*", zapcore.WarnLevel)
+
+	if loglvls.String() != "Internal.LogLevels:info,Server.Process.Worker:warn,Server.Queue:info" {
+		t.Errorf("levels: got '%s'", loglvls.String())
+	}
+
+	if !child.IsLogger("Worker") || !parent.IsLogger("Process.Worker") {
+		t.Error("IsLogger should resolve names within the prefix")
+	}
+}
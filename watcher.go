@@ -0,0 +1,286 @@
+package zaptool
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultWatchInterval is the interval a level file is polled at if the interval passed to
+// WatchLevelFile is not positive.
+const defaultWatchInterval = 10 * time.Second
+
+// ErrInvalidLevelFile is returned when a level file is not a flat mapping of names to levels.
+var ErrInvalidLevelFile = errors.New("invalid level file")
+
+// ParseLevelFile parses the contents of a level file, either a JSON object or a flat YAML
+// mapping of name (or wildcard pattern) to level, the rules are returned in file order.
+//
+//	# YAML patterns starting with "*" must be quoted.
+//	"*": info
+//	Server.*: debug
+//
+// Invalid entries are returned as a *SpecError.
+func ParseLevelFile(data []byte) ([]LevelRule, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return parseLevelFileJSON(trimmed)
+	}
+
+	return parseLevelFileYAML(trimmed)
+}
+
+// parseLevelFileJSON parses a JSON object of name to level, keeping the order of the keys.
+func parseLevelFileJSON(data []byte) ([]LevelRule, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidLevelFile, err)
+	}
+
+	out := []LevelRule{}
+	specErr := &SpecError{}
+
+	for dec.More() {
+		var name, lvl string
+
+		key, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidLevelFile, err)
+		}
+
+		name, _ = key.(string)
+
+		if err := dec.Decode(&lvl); err != nil {
+			return nil, fmt.Errorf("%w: value for %q: %w", ErrInvalidLevelFile, name, err)
+		}
+
+		out, specErr = appendLevelFileRule(out, specErr, name, lvl)
+	}
+
+	if len(specErr.Entries) > 0 {
+		return nil, specErr
+	}
+
+	return out, nil
+}
+
+// parseLevelFileYAML parses a flat YAML mapping of name to level, nested mappings, lists
+// and multi-line values are not supported.
+func parseLevelFileYAML(data []byte) ([]LevelRule, error) {
+	out := []LevelRule{}
+	specErr := &SpecError{}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line == "---" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		idx := strings.LastIndex(line, ":")
+		if idx <= 0 {
+			specErr.Entries = append(specErr.Entries, SpecEntryError{Entry: line, Err: ErrInvalidSpecEntry})
+			continue
+		}
+
+		lvl := line[idx+1:]
+		if c := strings.Index(lvl, " #"); c >= 0 {
+			lvl = lvl[:c]
+		}
+
+		out, specErr = appendLevelFileRule(out, specErr, unquoteYAML(line[:idx]), unquoteYAML(lvl))
+	}
+
+	if len(specErr.Entries) > 0 {
+		return nil, specErr
+	}
+
+	return out, nil
+}
+
+// appendLevelFileRule appends the rule if the name and level are valid, otherwise the
+// entry error is added to the SpecError.
+func appendLevelFileRule(out []LevelRule, specErr *SpecError, name, lvl string) ([]LevelRule, *SpecError) {
+	if err := ValidatePattern(name); err != nil {
+		specErr.Entries = append(specErr.Entries, SpecEntryError{Entry: name + ": " + lvl, Err: err})
+		return out, specErr
+	}
+
+	level, ok := parseLevel(lvl)
+	if !ok {
+		specErr.Entries = append(specErr.Entries, SpecEntryError{
+			Entry: name + ": " + lvl,
+			Err:   fmt.Errorf("%w: %q", ErrUnknownLevel, lvl),
+		})
+
+		return out, specErr
+	}
+
+	return append(out, LevelRule{Pattern: name, Level: level}), specErr
+}
+
+// unquoteYAML trims the value and removes matching single or double quotes.
+func unquoteYAML(v string) string {
+	v = strings.TrimSpace(v)
+
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1]
+	}
+
+	return v
+}
+
+// LevelFileWatcher applies the levels from a level file to a LogManager and reapplies them
+// whenever the file changes.
+type LevelFileWatcher struct {
+	path     string
+	logmgr   LogManager
+	logger   *zap.Logger
+	interval time.Duration
+	lock     sync.Mutex
+	content  []byte
+	applied  []string
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// WatchLevelFile applies the levels in the file at path (see ParseLevelFile) to the LogManager
+// and polls the file for changes at the interval until Stop is called.
+//
+// Rules that are removed from the file are cleared (if the LogManager supports ClearLevel),
+// if the file can not be read or parsed the error is logged to the Internal.LogLevels logger
+// and the current levels are left unchanged. A LogLevels applies the whole file as a single
+// change (as ApplySpec does), other LogManagers set each level in turn.
+//
+// If the interval is not positive (eg. unset in a config file) the file is polled every
+// 10 seconds.
+func WatchLevelFile(path string, logmgr LogManager, interval time.Duration) *LevelFileWatcher {
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	w := &LevelFileWatcher{
+		path:     path,
+		logmgr:   logmgr,
+		logger:   internalLogger(logmgr),
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	if err := w.Reload(); err != nil {
+		w.logger.Warn("unable to load level file", zap.String("path", path), zap.Error(err))
+	}
+
+	go w.run()
+
+	return w
+}
+
+// internalLogger returns the logger for errors about the LogManager, a LogLevels (or a
+// SubLogLevels of one) uses its own Internal.LogLevels logger so no level is created under
+// the prefix of a SubLogLevels.
+func internalLogger(logmgr LogManager) *zap.Logger {
+	switch m := logmgr.(type) {
+	case *LogLevels:
+		return m.iLogger
+	case *SubLogLevels:
+		return internalLogger(m.logmgr)
+	}
+
+	return logmgr.Named("Internal.LogLevels")
+}
+
+// run polls the file until the watcher is stopped.
+func (w *LevelFileWatcher) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if err := w.Reload(); err != nil {
+				w.logger.Warn("unable to reload level file", zap.String("path", w.path), zap.Error(err))
+			}
+		}
+	}
+}
+
+// Reload reads the file and applies the levels if the contents have changed since
+// they were last read, an error is only returned the first time invalid contents are read.
+func (w *LevelFileWatcher) Reload() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return fmt.Errorf("unable to read level file: %w", err)
+	}
+
+	if w.content != nil && bytes.Equal(data, w.content) {
+		return nil
+	}
+
+	w.content = data
+
+	rules, err := ParseLevelFile(data)
+	if err != nil {
+		return err
+	}
+
+	current := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		current[rule.Pattern] = true
+	}
+
+	src := ChangeSource{Origin: "file", Reason: "level file " + w.path + " changed"}
+	cleared := []string{}
+
+	for _, pattern := range w.applied {
+		if !current[pattern] {
+			cleared = append(cleared, pattern)
+		}
+	}
+
+	w.applied = w.applied[:0]
+	for _, rule := range rules {
+		w.applied = append(w.applied, rule.Pattern)
+	}
+
+	if ll, ok := w.logmgr.(*LogLevels); ok {
+		ll.applyRulesBy(cleared, rules, src)
+	} else {
+		for _, pattern := range cleared {
+			clearLevelBy(w.logmgr, pattern, src)
+		}
+
+		for _, rule := range rules {
+			setLevelBy(w.logmgr, rule.Pattern, rule.Level, src)
+		}
+	}
+
+	w.logger.Info("applied level file", zap.String("path", w.path), zap.Int("rules", len(rules)))
+
+	return nil
+}
+
+// Stop stops polling the file, the applied levels are left in place.
+func (w *LevelFileWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+
+	<-w.done
+}
@@ -0,0 +1,188 @@
+package zaptool_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/na4ma4/go-zaptool"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestParseLevelFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		expect string
+	}{
+		{"yaml", "---\n# comment\n\"*\": info\nServer.*: debug # inline\n'Client': \"warn\"\n", "*:info,Server.*:debug,Client:warn"},
+		{"json", `{"*":"info","Server.*":"debug","Client":"warn"}`, "*:info,Server.*:debug,Client:warn"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := zaptool.ParseLevelFile([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("ParseLevelFile returned error: %s", err)
+			}
+
+			got := ""
+			for idx, rule := range rules {
+				if idx > 0 {
+					got += ","
+				}
+
+				got += rule.String()
+			}
+
+			if got != tt.expect {
+				t.Errorf("rules: got '%s', want '%s'", got, tt.expect)
+			}
+		})
+	}
+
+	_, err := zaptool.ParseLevelFile([]byte("Server.*: loud\nbogus\n"))
+
+	var specErr *zaptool.SpecError
+	if !errors.As(err, &specErr) || len(specErr.Entries) != 2 {
+		t.Errorf("ParseLevelFile should return a *SpecError with 2 entries: %v", err)
+	}
+
+	if _, err := zaptool.ParseLevelFile([]byte(`{"Server.*": 1}`)); !errors.Is(err, zaptool.ErrInvalidLevelFile) {
+		t.Errorf("ParseLevelFile should return ErrInvalidLevelFile: %v", err)
+	}
+}
+
+func TestWatchLevelFile(t *testing.T) {
+	fac, observedLogs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.Named("Server.Process")
+	loglvls.Named("Client")
+
+	path := filepath.Join(t.TempDir(), "levels.yaml")
+	if err := os.WriteFile(path, []byte("Server.*: debug\nClient: warn\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile returned error: %s", err)
+	}
+
+	watcher := zaptool.WatchLevelFile(path, loglvls, 10*time.Millisecond)
+	defer watcher.Stop()
+
+	if loglvls.String() != "Client:warn,Internal.LogLevels:info,Server.Process:debug" {
+		t.Errorf("levels: got '%s'", loglvls.String())
+	}
+
+	if err := os.WriteFile(path, []byte("Server.*: [debug\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile returned error: %s", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) && observedLogs.FilterLevelExact(zapcore.WarnLevel).Len() == 0 {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if observedLogs.FilterLoggerName("Internal.LogLevels").FilterLevelExact(zapcore.WarnLevel).Len() == 0 {
+		t.Error("parse errors should be logged to the Internal.LogLevels logger")
+	}
+
+	if loglvls.String() != "Client:warn,Internal.LogLevels:info,Server.Process:debug" {
+		t.Errorf("levels should not change when the file is invalid: %s", loglvls.String())
+	}
+
+	if err := os.WriteFile(path, []byte("Server.*: error\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile returned error: %s", err)
+	}
+
+	waitForLevels(t, loglvls, "Client:info,Internal.LogLevels:info,Server.Process:error")
+
+	watcher.Stop()
+	watcher.Stop()
+}
+
+func TestWatchLevelFile_SubLogLevels(t *testing.T) {
+	fac, observedLogs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	sublvls := zaptool.NewSubLogLevels("Server", loglvls)
+	sublvls.Named("Process")
+
+	path := filepath.Join(t.TempDir(), "levels.yaml")
+	if err := os.WriteFile(path, []byte("Process: [debug\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile returned error: %s", err)
+	}
+
+	watcher := zaptool.WatchLevelFile(path, sublvls, time.Hour)
+	watcher.Stop()
+
+	if loglvls.String() != "Internal.LogLevels:info,Server.Process:info" {
+		t.Errorf("the watcher should not create a level under the prefix: %s", loglvls.String())
+	}
+
+	if observedLogs.FilterLoggerName("Internal.LogLevels").FilterLevelExact(zapcore.WarnLevel).Len() != 1 {
+		t.Error("errors should be logged to the Internal.LogLevels logger")
+	}
+}
+
+func TestWatchLevelFile_SingleChange(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	store := &readingStore{MemoryStore: zaptool.NewMemoryStore()}
+	loglvls := zaptool.NewLogLevels(logger, zaptool.LogLevelsStore(store))
+	loglvls.Named("Server.Process")
+	loglvls.Named("Client")
+
+	store.ll = loglvls
+
+	path := filepath.Join(t.TempDir(), "levels.yaml")
+	if err := os.WriteFile(path, []byte("Server.*: debug\nClient: warn\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile returned error: %s", err)
+	}
+
+	watcher := zaptool.WatchLevelFile(path, loglvls, time.Hour)
+	defer watcher.Stop()
+
+	if err := os.WriteFile(path, []byte("Server.*: error\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile returned error: %s", err)
+	}
+
+	if err := watcher.Reload(); err != nil {
+		t.Fatalf("Reload returned error: %s", err)
+	}
+
+	want := []string{
+		"Client:warn,Internal.LogLevels:info,Server.Process:debug",
+		"Client:info,Internal.LogLevels:info,Server.Process:error",
+	}
+
+	if len(store.saved) != len(want) || store.saved[0] != want[0] || store.saved[1] != want[1] {
+		t.Errorf("each change to the file should be saved once: got %v, want %v", store.saved, want)
+	}
+}
+
+func TestWatchLevelFile_ZeroInterval(t *testing.T) {
+	fac, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(fac)
+
+	loglvls := zaptool.NewLogLevels(logger)
+	loglvls.Named("Server")
+
+	path := filepath.Join(t.TempDir(), "levels.yaml")
+	if err := os.WriteFile(path, []byte("Server: debug\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile returned error: %s", err)
+	}
+
+	watcher := zaptool.WatchLevelFile(path, loglvls, 0)
+	watcher.Stop()
+
+	if got := loglvls.String(); got != "Internal.LogLevels:info,Server:debug" {
+		t.Errorf("loglvls.String(): got '%s', want '%s'", got, "Internal.LogLevels:info,Server:debug")
+	}
+}